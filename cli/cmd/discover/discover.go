@@ -0,0 +1,89 @@
+package discover
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Excoriate/go-terradagger/cli/internal/tui"
+	"github.com/Excoriate/go-terradagger/pkg/discovery"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	rootsOnly    bool
+	changedSince string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Discover the terraform modules and terragrunt units in the workspace, and print them as JSON",
+	Run: func(cmd *cobra.Command, args []string) {
+		ux := &struct {
+			Msg tui.MessageWriter
+		}{
+			Msg: tui.NewMessageWriter(),
+		}
+
+		// The discovery only reads the workspace, so the Dagger engine isn't started.
+		td := terradagger.New(cmd.Context(), &terradagger.Options{
+			Workspace: viper.GetString("workspace"),
+		})
+
+		defer td.Close()
+
+		modules, err := discovery.Discover(td)
+		if err != nil {
+			ux.Msg.ShowError(tui.MessageOptions{
+				Message: "Unable to discover the modules",
+				Error:   err,
+			})
+			return
+		}
+
+		if baseRef := viper.GetString("changed-since"); baseRef != "" {
+			changedFiles, err := discovery.GetChangedFiles(td.Config.GetWorkspaceAbs(), discovery.ChangesOptions{
+				BaseRef: baseRef,
+			})
+			if err != nil {
+				ux.Msg.ShowError(tui.MessageOptions{
+					Message: "Unable to detect the changed modules",
+					Error:   err,
+				})
+				return
+			}
+
+			modules = discovery.GetAffectedModules(modules, changedFiles, nil)
+		}
+
+		if viper.GetBool("roots-only") {
+			modules = discovery.GetRootModules(modules)
+		}
+
+		if modules == nil {
+			modules = []discovery.Module{}
+		}
+
+		// Version constraints (e.g. >= 1.5) are printed as they're written, instead of being escaped.
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(modules); err != nil {
+			ux.Msg.ShowError(tui.MessageOptions{
+				Message: "Unable to render the discovered modules",
+				Error:   err,
+			})
+		}
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&rootsOnly, "roots-only", "", false, "Only print the root modules and terragrunt units")
+
+	Cmd.Flags().StringVarP(&changedSince, "changed-since", "", "", "Only print the modules affected by the changes since this git ref (e.g. origin/main)")
+
+	_ = viper.BindPFlags(Cmd.Flags())
+}
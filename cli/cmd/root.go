@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 
+	"github.com/Excoriate/go-terradagger/cli/cmd/discover"
 	"github.com/Excoriate/go-terradagger/cli/cmd/tf"
 
 	"github.com/spf13/viper"
@@ -35,6 +36,7 @@ func init() {
 
 	rootCmd.AddCommand(tf.Cmd)
 	rootCmd.AddCommand(tf.TgCMD)
+	rootCmd.AddCommand(discover.Cmd)
 }
 
 func Execute() {
@@ -0,0 +1,104 @@
+package tf
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Excoriate/go-terradagger/cli/internal/tui"
+	"github.com/Excoriate/go-terradagger/pkg/plansummary"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraform"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	summaryFormat string
+	summaryOutput string
+)
+
+var SummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Run a terraform plan, and render a summary of it (markdown, text or json)",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Cancel the running commands (init and plan) on Ctrl+C, or when the CI job is terminated.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ux := &struct {
+			Msg tui.MessageWriter
+		}{
+			Msg: tui.NewMessageWriter(),
+		}
+
+		td := terradagger.New(ctx, &terradagger.Options{
+			Workspace: viper.GetString("workspace"),
+		})
+
+		// Start the engine (and the Dagger backend)
+		if err := td.StartEngine(); err != nil {
+			ux.Msg.ShowError(tui.MessageOptions{
+				Message: "Unable to start the Dagger engine",
+				Error:   err,
+			})
+			return
+		}
+
+		defer td.Close()
+
+		tfOptions :=
+			terraformcore.WithOptions(td, &terraformcore.TfOptions{
+				ModulePath:                   viper.GetString("module"),
+				EnableSSHPrivateGit:          true,
+				TerraformVersion:             viper.GetString("terraform-version"),
+				EnvVarsToInjectByKeyFromHost: []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"},
+			})
+
+		result, err := terraform.PlanResultE(td, tfOptions, terraform.PlanOptions{
+			TerraformVarFiles: viper.GetStringSlice("var-files"),
+		})
+		if err != nil {
+			ux.Msg.ShowError(tui.MessageOptions{
+				Message: "Unable to build the plan",
+				Error:   err,
+			})
+			return
+		}
+
+		out, err := plansummary.Render(result, viper.GetString("format"), plansummary.Options{
+			Module: viper.GetString("module"),
+		})
+		if err != nil {
+			ux.Msg.ShowError(tui.MessageOptions{
+				Message: "Unable to render the plan summary",
+				Error:   err,
+			})
+			return
+		}
+
+		if outputFile := viper.GetString("output"); outputFile != "" {
+			if err := os.WriteFile(outputFile, []byte(out), 0o600); err != nil {
+				ux.Msg.ShowError(tui.MessageOptions{
+					Message: fmt.Sprintf("Unable to write the plan summary into %s", outputFile),
+					Error:   err,
+				})
+			}
+			return
+		}
+
+		fmt.Println(out)
+	},
+}
+
+func init() {
+	SummaryCmd.Flags().StringVarP(&summaryFormat, "format", "", plansummary.FormatMarkdown, "The format of the summary: markdown, text or json")
+	SummaryCmd.Flags().StringVarP(&summaryOutput, "output", "o", "", "Write the summary into this file instead of the standard output")
+
+	_ = viper.BindPFlags(SummaryCmd.Flags())
+
+	Cmd.AddCommand(SummaryCmd)
+}
@@ -1,7 +1,9 @@
 package tf
 
 import (
-	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Excoriate/go-terradagger/cli/internal/tui"
 	"github.com/Excoriate/go-terradagger/pkg/terradagger"
@@ -12,11 +14,22 @@ import (
 	"github.com/spf13/viper"
 )
 
+var (
+	protectedAddresses []string
+	protectedTypes     []string
+	maxDeletions       int
+	allowDestructive   bool
+)
+
 var Cmd = &cobra.Command{
 	Use:   "tf",
 	Short: "Execute terraform commands using go-terradagger",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		// Cancel the running commands on Ctrl+C, or when the CI job is terminated. A running apply or destroy
+		// is interrupted gracefully (SIGINT), so terraform persists the state and releases the state lock.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		// Build the UX.
 		ux := &struct {
 			Msg   tui.MessageWriter
@@ -40,7 +53,7 @@ var Cmd = &cobra.Command{
 			})
 		}
 
-		defer td.Engine.GetEngine().Close()
+		defer td.Close()
 
 		tfOptions :=
 			terraformcore.WithOptions(td, &terraformcore.TfOptions{
@@ -73,8 +86,11 @@ var Cmd = &cobra.Command{
 			})
 		}
 
+		guardrails := getGuardrails()
+
 		_, tfApplyErr := terraform.ApplyE(td, tfOptions, terraform.ApplyOptions{
 			AutoApprove: true,
+			Guardrails:  guardrails,
 			Vars: []terraformcore.TFInputVariable{
 				{
 					Name:  "is_enabled",
@@ -91,6 +107,7 @@ var Cmd = &cobra.Command{
 
 		_, tfDestroyErr := terraform.DestroyE(td, tfOptions, terraform.DestroyOptions{
 			AutoApprove: true,
+			Guardrails:  guardrails,
 			Vars: []terraformcore.TFInputVariable{
 				{
 					Name:  "is_enabled",
@@ -106,3 +123,30 @@ var Cmd = &cobra.Command{
 		}
 	},
 }
+
+// getGuardrails returns the guardrails configured through the flags, or nil if none were set.
+func getGuardrails() *terraformcore.Guardrails {
+	addresses := viper.GetStringSlice("protect-address")
+	types := viper.GetStringSlice("protect-type")
+	maxDel := viper.GetInt("max-deletions")
+
+	if len(addresses) == 0 && len(types) == 0 && maxDel == 0 {
+		return nil
+	}
+
+	return &terraformcore.Guardrails{
+		ProtectedAddresses: addresses,
+		ProtectedTypes:     types,
+		MaxDeletions:       maxDel,
+		Override:           viper.GetBool("allow-destructive"),
+	}
+}
+
+func init() {
+	Cmd.Flags().StringSliceVarP(&protectedAddresses, "protect-address", "", []string{}, "Refuse to destroy or replace resources whose address matches these patterns")
+	Cmd.Flags().StringSliceVarP(&protectedTypes, "protect-type", "", []string{}, "Refuse to destroy or replace resources of these types")
+	Cmd.Flags().IntVarP(&maxDeletions, "max-deletions", "", 0, "Refuse to run if the plan destroys or replaces more than this number of resources")
+	Cmd.Flags().BoolVarP(&allowDestructive, "allow-destructive", "", false, "Explicitly allow destructive changes that violate the guardrails")
+
+	_ = viper.BindPFlags(Cmd.Flags())
+}
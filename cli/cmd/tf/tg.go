@@ -1,7 +1,9 @@
 package tf
 
 import (
-	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Excoriate/go-terradagger/pkg/terragrunt"
 
@@ -16,8 +18,12 @@ import (
 var TgCMD = &cobra.Command{
 	Use:   "tg",
 	Short: "Execute terraform commands using go-terradagger",
+	Long:  "Execute terragrunt init and plan using go-terradagger. Nothing is applied or destroyed, so the guardrail flags of tf don't apply.",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		// Cancel the running commands (init and plan) on Ctrl+C, or when the CI job is terminated.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		// Build the UX.
 		ux := &struct {
 			Msg   tui.MessageWriter
@@ -41,7 +47,7 @@ var TgCMD = &cobra.Command{
 			})
 		}
 
-		defer td.Engine.GetEngine().Close()
+		defer td.Close()
 
 		tgOptions :=
 			terraformcore.WithOptions(td, &terraformcore.TfOptions{
@@ -3,6 +3,7 @@ package container
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"dagger.io/dagger"
 )
 
+// HomeDir is the home directory of the user that runs the commands in the container.
+const HomeDir = "/root"
+
 type Config struct {
 	Workdir              string
 	MountPathAbs         string
@@ -20,6 +24,38 @@ type Config struct {
 	MountPathPrefix      string
 	KeepEntryPoint       bool
 	InvalidateCache      bool
+	StepLog              *StepLog
+	ServiceBindings      []ServiceBinding
+	Mounts               []Mount
+	GitHTTPSCredentials  []GitHTTPSCredential
+	GitURLRewrites       map[string]string
+	// SSHKnownHosts is the content of the known_hosts file used by git over SSH. If it's empty, the host
+	// keys aren't verified (new keys are accepted).
+	SSHKnownHosts string
+	// StrictHostKeyChecking rejects the hosts that aren't in SSHKnownHosts.
+	StrictHostKeyChecking bool
+	// CACertificates are extra CA certificates (PEM encoded), added to the trust store of the container.
+	CACertificates []string
+	// Proxy is the HTTP proxy used by terraform, terragrunt, the providers and git.
+	Proxy *Proxy
+	// TerraformCLIConfig is the content of the terraform CLI configuration (.terraformrc), e.g. to install
+	// the providers from a mirror.
+	TerraformCLIConfig string
+	// BaseContainer, if set, is used instead of the image of ContainerImage, e.g. a toolchain built
+	// with BuildToolchain.
+	BaseContainer *dagger.Container
+	// RegistryCredentials authenticate the pull of the image, from private registries.
+	RegistryCredentials []RegistryCredential
+	// Platform is the platform of the image, e.g. linux/amd64. If it's empty, the native platform of the
+	// Dagger engine is used.
+	Platform string
+}
+
+// ServiceBinding is a service (e.g. a database, or an S3-compatible server) that's reachable from the
+// container through its alias, used as the hostname.
+type ServiceBinding struct {
+	Alias   string
+	Service *dagger.Service
 }
 
 type EnvVar struct {
@@ -64,6 +100,19 @@ type Container interface {
 	GetCacheBusterEnvVar() EnvVar
 	GetGitSSHEnvVar() EnvVar
 	GetSSHAuthSockEnvVar() EnvVar
+	GetStepLog() *StepLog
+	GetServiceBindings() []ServiceBinding
+	GetMounts() []Mount
+	GetGitHTTPSCredentials() []GitHTTPSCredential
+	GetGitURLRewrites() map[string]string
+	GetSSHKnownHosts() string
+	GetSSHKnownHostsPath() string
+	GetCACertificates() []string
+	GetProxy() *Proxy
+	GetTerraformCLIConfig() string
+	GetBaseContainer() *dagger.Container
+	GetRegistryCredentials() []RegistryCredential
+	GetPlatform() string
 }
 
 func (o *Config) GetMountDir(client *dagger.Client) *dagger.Directory {
@@ -121,9 +170,69 @@ func (o *Config) GetCacheBusterEnvVar() EnvVar {
 }
 
 func (o *Config) GetGitSSHEnvVar() EnvVar {
-	return gitSSHEnvVar
+	if o.SSHKnownHosts == "" {
+		return gitSSHEnvVar
+	}
+
+	return EnvVar{
+		Name:   gitSSHEnvVar.Name,
+		Value:  utils.GetSSHGitKnownHostsConnectCommand(o.GetSSHKnownHostsPath(), o.StrictHostKeyChecking),
+		Expand: false,
+	}
 }
 
 func (o *Config) GetSSHAuthSockEnvVar() EnvVar {
 	return sshAuthSockEnvVar
 }
+
+func (o *Config) GetStepLog() *StepLog {
+	return o.StepLog
+}
+
+func (o *Config) GetServiceBindings() []ServiceBinding {
+	return o.ServiceBindings
+}
+
+func (o *Config) GetMounts() []Mount {
+	return o.Mounts
+}
+
+func (o *Config) GetGitHTTPSCredentials() []GitHTTPSCredential {
+	return o.GitHTTPSCredentials
+}
+
+func (o *Config) GetGitURLRewrites() map[string]string {
+	return o.GitURLRewrites
+}
+
+func (o *Config) GetSSHKnownHosts() string {
+	return o.SSHKnownHosts
+}
+
+func (o *Config) GetSSHKnownHostsPath() string {
+	return path.Join(HomeDir, ".ssh", "known_hosts")
+}
+
+func (o *Config) GetCACertificates() []string {
+	return o.CACertificates
+}
+
+func (o *Config) GetProxy() *Proxy {
+	return o.Proxy
+}
+
+func (o *Config) GetTerraformCLIConfig() string {
+	return o.TerraformCLIConfig
+}
+
+func (o *Config) GetBaseContainer() *dagger.Container {
+	return o.BaseContainer
+}
+
+func (o *Config) GetRegistryCredentials() []RegistryCredential {
+	return o.RegistryCredentials
+}
+
+func (o *Config) GetPlatform() string {
+	return o.Platform
+}
@@ -112,3 +112,21 @@ func TestConfig_GetSSHAuthSockEnvVar(t *testing.T) {
 		t.Errorf("Config.GetSSHAuthSockEnvVar() = %v, want %v", got, want)
 	}
 }
+
+func TestConfig_GetGitSSHEnvVar_KnownHosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"known hosts", Config{SSHKnownHosts: "github.com ssh-ed25519 AAAA"}, "ssh -o UserKnownHostsFile=/root/.ssh/known_hosts -o StrictHostKeyChecking=accept-new"},
+		{"strict", Config{SSHKnownHosts: "github.com ssh-ed25519 AAAA", StrictHostKeyChecking: true}, "ssh -o UserKnownHostsFile=/root/.ssh/known_hosts -o StrictHostKeyChecking=yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetGitSSHEnvVar(); got.Value != tt.want {
+				t.Errorf("Config.GetGitSSHEnvVar() = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package container
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const defaultGitHTTPSUsername = "x-access-token"
+
+// GitHTTPSCredential is a token used to clone the private modules with git::https:// sources. It's
+// written to the ~/.netrc of the container, as a Dagger secret.
+type GitHTTPSCredential struct {
+	// Host is the git host, e.g. github.com
+	Host string
+	// Username is the user of the token. Defaults to x-access-token (GitHub); GitLab uses oauth2.
+	Username string
+	// Token is the token, e.g. a GitHub personal access token, or a GitLab CI job token.
+	Token string
+}
+
+// Validate checks that the credential has a host and a token.
+func (c *GitHTTPSCredential) Validate() error {
+	if c.Host == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError("the host of the git HTTPS credential is empty", nil)
+	}
+
+	if c.Token == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the token of the git HTTPS credential for %s is empty", c.Host), nil)
+	}
+
+	return nil
+}
+
+// getNetrc returns the content of the .netrc file with the credentials.
+func getNetrc(credentials []GitHTTPSCredential) string {
+	var netrc strings.Builder
+	for _, credential := range credentials {
+		username := credential.Username
+		if username == "" {
+			username = defaultGitHTTPSUsername
+		}
+
+		netrc.WriteString(fmt.Sprintf("machine %s\nlogin %s\npassword %s\n", credential.Host, username, credential.Token))
+	}
+
+	return netrc.String()
+}
+
+// getGitURLRewritesEnvVars returns the env vars that configure the url.<base>.insteadOf rewrites in
+// git, without a .gitconfig file. The rewrites are keyed by the URL prefix to rewrite (e.g.
+// git@github.com:), and valued by its replacement (e.g. https://github.com/).
+func getGitURLRewritesEnvVars(rewrites map[string]string) map[string]string {
+	prefixes := make([]string, 0, len(rewrites))
+	for prefix := range rewrites {
+		prefixes = append(prefixes, prefix)
+	}
+
+	sort.Strings(prefixes)
+
+	envVars := map[string]string{}
+	for i, prefix := range prefixes {
+		envVars[fmt.Sprintf("GIT_CONFIG_KEY_%d", i)] = fmt.Sprintf("url.%s.insteadOf", rewrites[prefix])
+		envVars[fmt.Sprintf("GIT_CONFIG_VALUE_%d", i)] = prefix
+	}
+
+	if len(prefixes) > 0 {
+		envVars["GIT_CONFIG_COUNT"] = fmt.Sprintf("%d", len(prefixes))
+	}
+
+	return envVars
+}
+
+// addGitHTTPSAuth adds the .netrc with the git HTTPS credentials, and the git URL rewrites.
+func (r *runtime) addGitHTTPSAuth(container *dagger.Container) *dagger.Container {
+	if credentials := r.container.GetGitHTTPSCredentials(); len(credentials) > 0 {
+		netrc := setSecret(r.td.Engine.GetEngine(), "terradagger-netrc", getNetrc(credentials))
+		container = container.WithMountedSecret(path.Join(HomeDir, ".netrc"), netrc)
+	}
+
+	return r.AddEnvVars(getGitURLRewritesEnvVars(r.container.GetGitURLRewrites()), container)
+}
@@ -0,0 +1,46 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetNetrc(t *testing.T) {
+	netrc := getNetrc([]GitHTTPSCredential{
+		{Host: "github.com", Token: "ghp_token"},
+		{Host: "gitlab.com", Username: "oauth2", Token: "glpat"},
+	})
+
+	want := "machine github.com\nlogin x-access-token\npassword ghp_token\nmachine gitlab.com\nlogin oauth2\npassword glpat\n"
+	if netrc != want {
+		t.Errorf("getNetrc() = %q, want %q", netrc, want)
+	}
+}
+
+func TestGetGitURLRewritesEnvVars(t *testing.T) {
+	envVars := getGitURLRewritesEnvVars(map[string]string{
+		"ssh://git@github.com/": "https://github.com/",
+		"git@github.com:":       "https://github.com/",
+	})
+
+	want := map[string]string{
+		"GIT_CONFIG_COUNT":   "2",
+		"GIT_CONFIG_KEY_0":   "url.https://github.com/.insteadOf",
+		"GIT_CONFIG_VALUE_0": "git@github.com:",
+		"GIT_CONFIG_KEY_1":   "url.https://github.com/.insteadOf",
+		"GIT_CONFIG_VALUE_1": "ssh://git@github.com/",
+	}
+	if !reflect.DeepEqual(envVars, want) {
+		t.Errorf("getGitURLRewritesEnvVars() = %v, want %v", envVars, want)
+	}
+
+	if envVars := getGitURLRewritesEnvVars(nil); len(envVars) != 0 {
+		t.Errorf("getGitURLRewritesEnvVars(nil) = %v, want no env vars", envVars)
+	}
+}
+
+func TestGitHTTPSCredential_Validate(t *testing.T) {
+	if err := (&GitHTTPSCredential{Host: "github.com"}).Validate(); err == nil {
+		t.Errorf("GitHTTPSCredential.Validate() expected an error for an empty token")
+	}
+}
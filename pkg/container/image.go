@@ -2,6 +2,7 @@ package container
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Excoriate/go-terradagger/pkg/config"
 )
@@ -66,9 +67,28 @@ func (o *ImageConfig) GetVersion() string {
 }
 
 func (o *ImageConfig) GetTerraformContainerImage() string {
-	return fmt.Sprintf("%s:%s", o.GetImageTerraform(), o.GetVersion())
+	return getImageReference(o.GetImageTerraform(), o.GetVersion())
 }
 
 func (o *ImageConfig) GetTerragruntContainerImage() string {
-	return fmt.Sprintf("%s:%s", o.GetImageTerragrunt(), o.GetVersion())
+	return getImageReference(o.GetImageTerragrunt(), o.GetVersion())
+}
+
+// IsDigestReference returns true if the image is pinned by digest, e.g. hashicorp/terraform@sha256:...
+func IsDigestReference(image string) bool {
+	return strings.Contains(image, "@sha256:")
+}
+
+// getImageReference returns the reference of the image. An image that's already pinned by digest is
+// used as is, and a version that's a digest (sha256:...) pins the image by digest instead of by tag.
+func getImageReference(image, version string) string {
+	if IsDigestReference(image) {
+		return image
+	}
+
+	if strings.HasPrefix(version, "sha256:") {
+		return fmt.Sprintf("%s@%s", image, version)
+	}
+
+	return fmt.Sprintf("%s:%s", image, version)
 }
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Excoriate/go-terradagger/pkg/config"
@@ -82,3 +83,24 @@ func TestImageConfig_GetTerragruntContainerImage(t *testing.T) {
 		t.Errorf("GetTerragruntContainerImage() = %v, want %v", got, expected)
 	}
 }
+
+func TestImageConfig_GetTerraformContainerImage_Digest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		image   string
+		version string
+		want    string
+	}{
+		{"hashicorp/terraform", "1.7.5", "hashicorp/terraform:1.7.5"},
+		{"hashicorp/terraform", digest, "hashicorp/terraform@" + digest},
+		{"hashicorp/terraform@" + digest, "1.7.5", "hashicorp/terraform@" + digest},
+		{"hashicorp/terraform:1.7.5@" + digest, "", "hashicorp/terraform:1.7.5@" + digest},
+	}
+
+	for _, tt := range tests {
+		if got := NewImageConfig(tt.image, tt.version).GetTerraformContainerImage(); got != tt.want {
+			t.Errorf("GetTerraformContainerImage() = %v, want %v", got, tt.want)
+		}
+	}
+}
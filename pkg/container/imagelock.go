@@ -0,0 +1,187 @@
+package container
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+// ImageLockFile is the name of the lock file with the digests of the images, in the .terradagger
+// directory of the workspace.
+const ImageLockFile = "images.lock.json"
+
+// imageLockMu serializes the updates of the lock file, when many modules run concurrently.
+var imageLockMu sync.Mutex
+
+// ImageLock records the digest resolved for each image reference, so every run uses the same images.
+type ImageLock struct {
+	// Images are the digests (sha256:...), keyed by image reference, e.g. hashicorp/terraform:1.7.5
+	Images map[string]string `json:"images"`
+}
+
+// LoadImageLock reads the lock file. If it doesn't exist, the lock is empty.
+func LoadImageLock(lockFile string) (*ImageLock, error) {
+	lock := &ImageLock{Images: map[string]string{}}
+
+	content, err := os.ReadFile(lockFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+
+	if err != nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to read the image lock file %s", lockFile), err)
+	}
+
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the image lock file %s isn't valid", lockFile), err)
+	}
+
+	if lock.Images == nil {
+		lock.Images = map[string]string{}
+	}
+
+	return lock, nil
+}
+
+// Save writes the lock file, creating its directory if needed.
+func (l *ImageLock) Save(lockFile string) error {
+	// The keys of a JSON map are always sorted, so the file is stable between runs.
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lockFile), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(lockFile, append(content, '\n'), 0o644)
+}
+
+// GetImages returns the image references of the lock, sorted.
+func (l *ImageLock) GetImages() []string {
+	images := make([]string, 0, len(l.Images))
+	for image := range l.Images {
+		images = append(images, image)
+	}
+
+	sort.Strings(images)
+
+	return images
+}
+
+// Check returns the digest to run the image with, and whether it's new in the lock. A new image uses
+// its resolved digest. If the resolved digest isn't the one of the lock, the one of the lock is used,
+// or in strict mode, it's an erroer.ErrTerraDaggerImageDigestMismatchError.
+func (l *ImageLock) Check(image, digest string, strict bool) (string, bool, error) {
+	locked, ok := l.Images[image]
+	if !ok {
+		return digest, true, nil
+	}
+
+	if locked != digest && strict {
+		return "", false, erroer.NewErrTerraDaggerImageDigestMismatchError(image, locked, digest)
+	}
+
+	return locked, false, nil
+}
+
+// getDigestFromImageRef returns the digest of a reference pinned by digest, e.g. the sha256:... of
+// docker.io/hashicorp/terraform:1.7.5@sha256:...
+func getDigestFromImageRef(imageRef string) (string, error) {
+	_, digest, ok := strings.Cut(imageRef, "@")
+	if !ok || !strings.HasPrefix(digest, "sha256:") {
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the image reference %s has no digest", imageRef), nil)
+	}
+
+	return digest, nil
+}
+
+// getImageRepository returns the image without its tag or digest, e.g. hashicorp/terraform of
+// hashicorp/terraform:1.7.5. The port of a registry (registry.example.com:5000/terraform) is kept.
+func getImageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+
+	return image
+}
+
+// ResolveImageDigest resolves the digest of the image in its registry, without running it. The
+// registry credentials are used for the private registries. The platform (e.g. linux/amd64) is the one
+// the image runs on, so the digest is the one that's pulled. If it's empty, it's the engine's platform.
+func ResolveImageDigest(td *terradagger.TD, image, platform string, registryCredentials []RegistryCredential) (string, error) {
+	client := td.Engine.GetEngine()
+	if client == nil {
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the dagger engine must be started before resolving the image %s", image), nil)
+	}
+
+	containerOpts := dagger.ContainerOpts{}
+	if platform != "" {
+		containerOpts.Platform = dagger.Platform(platform)
+	}
+
+	imageRef, err := withRegistryAuth(client, client.Container(containerOpts), registryCredentials).From(image).ImageRef(td.Ctx)
+	if err != nil {
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to resolve the digest of the image %s", image), err)
+	}
+
+	return getDigestFromImageRef(imageRef)
+}
+
+// PinImage resolves the digest of the image, checks it against the lock file (recording it if it's
+// new), and returns the image pinned by the digest of the lock, so every run uses the same image. To
+// update an image, remove it from the lock file. An image that's already pinned by digest is returned as is.
+// An image of the lock isn't resolved again, unless strict mode checks that its tag didn't move, so the
+// locked images run without access to their registry.
+func PinImage(td *terradagger.TD, image, platform, lockFile string, strict bool, registryCredentials []RegistryCredential) (string, error) {
+	if IsDigestReference(image) {
+		return image, nil
+	}
+
+	imageLockMu.Lock()
+	defer imageLockMu.Unlock()
+
+	lock, err := LoadImageLock(lockFile)
+	if err != nil {
+		return "", err
+	}
+
+	if locked, ok := lock.Images[image]; ok && !strict {
+		return fmt.Sprintf("%s@%s", getImageRepository(image), locked), nil
+	}
+
+	digest, err := ResolveImageDigest(td, image, platform, registryCredentials)
+	if err != nil {
+		return "", err
+	}
+
+	lockedDigest, isNew, err := lock.Check(image, digest, strict)
+	if err != nil {
+		return "", err
+	}
+
+	if lockedDigest != digest {
+		td.Log.Warn(fmt.Sprintf("the image %s resolved the digest %s, but %s is used, as recorded in %s", image, digest, lockedDigest, lockFile))
+	}
+
+	if isNew {
+		lock.Images[image] = digest
+		if err := lock.Save(lockFile); err != nil {
+			return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to write the image lock file %s", lockFile), err)
+		}
+	}
+
+	return fmt.Sprintf("%s@%s", getImageRepository(image), lockedDigest), nil
+}
@@ -0,0 +1,114 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+func TestImageLock_SaveAndLoad(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), ".terradagger", ImageLockFile)
+
+	lock, err := LoadImageLock(lockFile)
+	if err != nil || len(lock.Images) != 0 {
+		t.Fatalf("LoadImageLock() = %v, %v, want an empty lock", lock, err)
+	}
+
+	lock.Images["hashicorp/terraform:1.7.5"] = "sha256:" + strings.Repeat("a", 64)
+	lock.Images["alpine/terragrunt:1.7.5"] = "sha256:" + strings.Repeat("b", 64)
+	if err := lock.Save(lockFile); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadImageLock(lockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.Images, lock.Images) {
+		t.Errorf("LoadImageLock() = %v, want %v", loaded.Images, lock.Images)
+	}
+
+	if got := loaded.GetImages(); !reflect.DeepEqual(got, []string{"alpine/terragrunt:1.7.5", "hashicorp/terraform:1.7.5"}) {
+		t.Errorf("GetImages() = %v", got)
+	}
+}
+
+func TestImageLock_Check(t *testing.T) {
+	locked := "sha256:" + strings.Repeat("a", 64)
+	resolved := "sha256:" + strings.Repeat("b", 64)
+	lock := &ImageLock{Images: map[string]string{"hashicorp/terraform:1.7.5": locked}}
+
+	digest, isNew, err := lock.Check("hashicorp/terraform:1.7.6", resolved, true)
+	if digest != resolved || !isNew || err != nil {
+		t.Errorf("Check() of a new image = %s, %v, %v", digest, isNew, err)
+	}
+
+	digest, isNew, err = lock.Check("hashicorp/terraform:1.7.5", resolved, false)
+	if digest != locked || isNew || err != nil {
+		t.Errorf("Check() of a changed image = %s, %v, %v, want the locked digest", digest, isNew, err)
+	}
+
+	var mismatchErr *erroer.ErrTerraDaggerImageDigestMismatchError
+	if _, _, err := lock.Check("hashicorp/terraform:1.7.5", resolved, true); !errors.As(err, &mismatchErr) {
+		t.Errorf("Check() in strict mode = %v, want an ErrTerraDaggerImageDigestMismatchError", err)
+	}
+}
+
+func TestGetImageRepository(t *testing.T) {
+	tests := map[string]string{
+		"hashicorp/terraform:1.7.5":                     "hashicorp/terraform",
+		"hashicorp/terraform":                           "hashicorp/terraform",
+		"registry.example.com:5000/terraform:1.7.5":     "registry.example.com:5000/terraform",
+		"registry.example.com:5000/terraform":           "registry.example.com:5000/terraform",
+		"hashicorp/terraform:1.7.5@sha256:0123456789ab": "hashicorp/terraform",
+	}
+
+	for image, want := range tests {
+		if got := getImageRepository(image); got != want {
+			t.Errorf("getImageRepository(%s) = %s, want %s", image, got, want)
+		}
+	}
+}
+
+func TestGetDigestFromImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	if got, err := getDigestFromImageRef("docker.io/hashicorp/terraform:1.7.5@" + digest); got != digest || err != nil {
+		t.Errorf("getDigestFromImageRef() = %s, %v", got, err)
+	}
+
+	if _, err := getDigestFromImageRef("docker.io/hashicorp/terraform:1.7.5"); err == nil {
+		t.Error("getDigestFromImageRef() without a digest should fail")
+	}
+}
+
+func TestPinImage_Locked(t *testing.T) {
+	td := terradagger.New(context.Background(), &terradagger.Options{})
+	lockFile := filepath.Join(t.TempDir(), ImageLockFile)
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	lock := &ImageLock{Images: map[string]string{"hashicorp/terraform:1.7.5": digest}}
+	if err := lock.Save(lockFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// The engine isn't started: a locked image isn't resolved in its registry.
+	got, err := PinImage(td, "hashicorp/terraform:1.7.5", "linux/amd64", lockFile, false, nil)
+	if got != "hashicorp/terraform@"+digest || err != nil {
+		t.Errorf("PinImage() of a locked image = %s, %v", got, err)
+	}
+
+	if _, err := PinImage(td, "hashicorp/terraform:1.7.5", "linux/amd64", lockFile, true, nil); err == nil {
+		t.Error("PinImage() in strict mode should resolve the image, and fail without the engine")
+	}
+
+	if _, err := PinImage(td, "hashicorp/terraform:1.7.6", "linux/amd64", lockFile, false, nil); err == nil {
+		t.Error("PinImage() of a new image should resolve it, and fail without the engine")
+	}
+}
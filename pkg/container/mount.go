@@ -0,0 +1,145 @@
+package container
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// Mount is a file or a directory of the host that's mounted into the container. The container sees
+// a copy, so the host path is never modified.
+type Mount struct {
+	// HostPath is the absolute path of the file or directory in the host.
+	HostPath string
+	// ContainerPath is the absolute path where it's mounted in the container.
+	ContainerPath string
+	// Secret mounts the file (or each file of the directory) as a Dagger secret, so its content isn't
+	// stored in the cache, nor shown in the logs. Use it for credentials.
+	Secret bool
+	// Exclude are the subdirectories (relative to HostPath) that aren't mounted, e.g. logs.
+	Exclude []string
+}
+
+// Validate checks that the host path exists.
+func (m *Mount) Validate() error {
+	if m.HostPath == "" || m.ContainerPath == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError("the host path and the container path of the mount are required", nil)
+	}
+
+	if _, err := os.Stat(m.HostPath); err != nil {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the host path %s cannot be mounted", m.HostPath), err)
+	}
+
+	return nil
+}
+
+// getSecretFiles returns the files of the mount, keyed by their path in the container, and valued by
+// their path in the host. Symlinks and excluded subdirectories are skipped.
+func (m *Mount) getSecretFiles() (map[string]string, error) {
+	info, err := os.Stat(m.HostPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return map[string]string{m.ContainerPath: m.HostPath}, nil
+	}
+
+	files := map[string]string{}
+	err = filepath.WalkDir(m.HostPath, func(hostPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(m.HostPath, hostPath)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if m.isExcluded(relPath) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.Type().IsRegular() {
+			files[path.Join(m.ContainerPath, filepath.ToSlash(relPath))] = hostPath
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+func (m *Mount) isExcluded(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, excluded := range m.Exclude {
+		excluded = strings.Trim(filepath.ToSlash(excluded), "/")
+		if relPath == excluded || strings.HasPrefix(relPath, excluded+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addMounts mounts the host files and directories into the container.
+func (r *runtime) addMounts(container *dagger.Container) *dagger.Container {
+	client := r.td.Engine.GetEngine()
+
+	for _, mount := range r.container.GetMounts() {
+		if !mount.Secret {
+			info, err := os.Stat(mount.HostPath)
+			if err != nil {
+				r.td.Log.Warn(fmt.Sprintf("skipping the mount of %s: %s", mount.HostPath, err))
+				continue
+			}
+
+			if info.IsDir() {
+				container = container.WithMountedDirectory(mount.ContainerPath, client.Host().Directory(mount.HostPath, dagger.HostDirectoryOpts{
+					Exclude: mount.Exclude,
+				}))
+			} else {
+				container = container.WithMountedFile(mount.ContainerPath, client.Host().File(mount.HostPath))
+			}
+
+			continue
+		}
+
+		files, err := mount.getSecretFiles()
+		if err != nil {
+			r.td.Log.Warn(fmt.Sprintf("skipping the mount of %s: %s", mount.HostPath, err))
+			continue
+		}
+
+		containerPaths := make([]string, 0, len(files))
+		for containerPath := range files {
+			containerPaths = append(containerPaths, containerPath)
+		}
+
+		sort.Strings(containerPaths)
+
+		for _, containerPath := range containerPaths {
+			content, err := os.ReadFile(files[containerPath])
+			if err != nil {
+				r.td.Log.Warn(fmt.Sprintf("skipping the mount of %s: %s", files[containerPath], err))
+				continue
+			}
+
+			secret := setSecret(client, fmt.Sprintf("terradagger-mount-%s", containerPath), string(content))
+			container = container.WithMountedSecret(containerPath, secret)
+		}
+	}
+
+	return container
+}
@@ -0,0 +1,46 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMount_GetSecretFiles(t *testing.T) {
+	hostDir := t.TempDir()
+	for _, file := range []string{"config", "credentials", "sso/cache/token.json", "logs/cli.log"} {
+		hostPath := filepath.Join(hostDir, file)
+		if err := os.MkdirAll(filepath.Dir(hostPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(hostPath, []byte("secret"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mount := Mount{HostPath: hostDir, ContainerPath: "/root/.aws", Secret: true, Exclude: []string{"logs"}}
+	if err := mount.Validate(); err != nil {
+		t.Fatalf("Mount.Validate() unexpected error: %v", err)
+	}
+
+	files, err := mount.getSecretFiles()
+	if err != nil {
+		t.Fatalf("Mount.getSecretFiles() unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"/root/.aws/config":               filepath.Join(hostDir, "config"),
+		"/root/.aws/credentials":          filepath.Join(hostDir, "credentials"),
+		"/root/.aws/sso/cache/token.json": filepath.Join(hostDir, "sso", "cache", "token.json"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Mount.getSecretFiles() = %v, want %v", files, want)
+	}
+
+	missing := Mount{HostPath: filepath.Join(hostDir, "missing"), ContainerPath: "/root/missing"}
+	if err := missing.Validate(); err == nil {
+		t.Errorf("Mount.Validate() expected an error for a missing host path")
+	}
+}
@@ -0,0 +1,143 @@
+package container
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// caCertificatesDir is where the extra CA certificates are added, in both Alpine and Debian based images.
+const caCertificatesDir = "/usr/local/share/ca-certificates"
+
+// updateCACertificatesCmd updates the trust store with the extra CA certificates. If the image has no
+// update-ca-certificates, they're appended to the bundle used by terraform, the providers and git. The
+// errors are kept, so a broken trust store fails here instead of as a TLS error later.
+const updateCACertificatesCmd = "if command -v update-ca-certificates >/dev/null 2>&1; then update-ca-certificates >/dev/null; " +
+	"else mkdir -p /etc/ssl/certs && cat " + caCertificatesDir + "/terradagger-*.crt >> /etc/ssl/certs/ca-certificates.crt; fi"
+
+// Proxy is the HTTP proxy used by terraform, terragrunt, the providers and git.
+type Proxy struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// GetProxyFromHost returns the proxy configured in the host (HTTP_PROXY, HTTPS_PROXY and NO_PROXY, or
+// their lowercase variants), or nil if there's none.
+func GetProxyFromHost() *Proxy {
+	getEnv := func(key string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+
+		return os.Getenv(strings.ToLower(key))
+	}
+
+	proxy := &Proxy{
+		HTTPProxy:  getEnv("HTTP_PROXY"),
+		HTTPSProxy: getEnv("HTTPS_PROXY"),
+		NoProxy:    getEnv("NO_PROXY"),
+	}
+
+	if proxy.HTTPProxy == "" && proxy.HTTPSProxy == "" {
+		return nil
+	}
+
+	return proxy
+}
+
+// GetEnvVars returns the proxy env vars, in both upper and lower case, since each tool reads a
+// different one (e.g. git and curl only read the lowercase http_proxy).
+func (p *Proxy) GetEnvVars() map[string]string {
+	envVars := map[string]string{}
+	for key, value := range map[string]string{
+		"HTTP_PROXY":  p.HTTPProxy,
+		"HTTPS_PROXY": p.HTTPSProxy,
+		"NO_PROXY":    p.NoProxy,
+	} {
+		if value != "" {
+			envVars[key] = value
+			envVars[strings.ToLower(key)] = value
+		}
+	}
+
+	return envVars
+}
+
+// WithNoProxy returns a copy of the proxy that also bypasses the given hosts, e.g. the aliases of the
+// services, which are only reachable directly.
+func (p *Proxy) WithNoProxy(hosts ...string) *Proxy {
+	noProxy := *p
+
+	var entries []string
+	existing := map[string]bool{}
+	for _, entry := range strings.Split(p.NoProxy, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+			existing[entry] = true
+		}
+	}
+
+	for _, host := range hosts {
+		if host != "" && !existing[host] {
+			entries = append(entries, host)
+			existing[host] = true
+		}
+	}
+
+	noProxy.NoProxy = strings.Join(entries, ",")
+
+	return &noProxy
+}
+
+// ReadCACertificate reads a CA certificate file of the host, and checks that it's PEM encoded, with
+// at least one certificate.
+func ReadCACertificate(certificateFile string) (string, error) {
+	content, err := os.ReadFile(certificateFile)
+	if err != nil {
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to read the CA certificate %s", certificateFile), err)
+	}
+
+	for rest := content; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the CA certificate %s isn't a PEM encoded certificate", certificateFile), nil)
+		}
+
+		if block.Type == "CERTIFICATE" {
+			return string(content), nil
+		}
+	}
+}
+
+// addNetworkConfig adds the extra CA certificates to the trust store of the container, and the proxy env
+// vars. The services bound to the container bypass the proxy.
+func (r *runtime) addNetworkConfig(container *dagger.Container) *dagger.Container {
+	if certificates := r.container.GetCACertificates(); len(certificates) > 0 {
+		for i, certificate := range certificates {
+			container = container.WithNewFile(path.Join(caCertificatesDir, fmt.Sprintf("terradagger-%d.crt", i)), dagger.ContainerWithNewFileOpts{
+				Contents:    certificate,
+				Permissions: 0o644,
+			})
+		}
+
+		container = container.WithExec([]string{"sh", "-c", updateCACertificatesCmd}, dagger.ContainerWithExecOpts{SkipEntrypoint: true})
+	}
+
+	if proxy := r.container.GetProxy(); proxy != nil {
+		var aliases []string
+		for _, binding := range r.container.GetServiceBindings() {
+			aliases = append(aliases, binding.Alias)
+		}
+
+		container = r.AddEnvVars(proxy.WithNoProxy(aliases...).GetEnvVars(), container)
+	}
+
+	return container
+}
@@ -0,0 +1,87 @@
+package container
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadCACertificate(t *testing.T) {
+	dir := t.TempDir()
+
+	certificateFile := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("certificate")})
+	if err := os.WriteFile(certificateFile, certificate, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadCACertificate(certificateFile)
+	if err != nil || got != string(certificate) {
+		t.Errorf("ReadCACertificate() = %q, %v, want the certificate", got, err)
+	}
+
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadCACertificate(invalidFile); err == nil {
+		t.Errorf("ReadCACertificate() expected an error for a file that isn't PEM")
+	}
+
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadCACertificate(keyFile); err == nil {
+		t.Errorf("ReadCACertificate() expected an error for a PEM file without certificates")
+	}
+}
+
+func TestProxy_GetEnvVars(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "http://proxy:3128")
+	t.Setenv("NO_PROXY", "localhost,.internal")
+
+	proxy := GetProxyFromHost()
+	if proxy == nil {
+		t.Fatal("GetProxyFromHost() = nil, want the proxy of the host")
+	}
+
+	want := map[string]string{
+		"HTTPS_PROXY": "http://proxy:3128",
+		"https_proxy": "http://proxy:3128",
+		"NO_PROXY":    "localhost,.internal",
+		"no_proxy":    "localhost,.internal",
+	}
+	if got := proxy.GetEnvVars(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Proxy.GetEnvVars() = %v, want %v", got, want)
+	}
+
+	t.Setenv("https_proxy", "")
+	if proxy := GetProxyFromHost(); proxy != nil {
+		t.Errorf("GetProxyFromHost() = %v, want nil", proxy)
+	}
+}
+
+func TestProxy_WithNoProxy(t *testing.T) {
+	proxy := &Proxy{HTTPSProxy: "http://proxy:3128", NoProxy: "localhost, .internal"}
+
+	got := proxy.WithNoProxy("s3", "localstack", "localhost", "")
+	if got.NoProxy != "localhost,.internal,s3,localstack" {
+		t.Errorf("Proxy.WithNoProxy().NoProxy = %s", got.NoProxy)
+	}
+
+	if proxy.NoProxy != "localhost, .internal" {
+		t.Errorf("Proxy.WithNoProxy() modified the proxy: %s", proxy.NoProxy)
+	}
+
+	if got := (&Proxy{HTTPProxy: "http://proxy:3128"}).WithNoProxy("s3"); got.GetEnvVars()["no_proxy"] != "s3" {
+		t.Errorf("Proxy.WithNoProxy().GetEnvVars() = %v", got.GetEnvVars())
+	}
+}
@@ -0,0 +1,52 @@
+package container
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+// platformRegex matches the platforms of the containers, e.g. linux/amd64 or linux/arm/v7.
+var platformRegex = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9]+(/[a-z0-9]+)?$`)
+
+// ValidatePlatform checks that the platform is like linux/amd64.
+func ValidatePlatform(platform string) error {
+	if !platformRegex.MatchString(platform) {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the platform %s isn't valid, it should be like linux/amd64", platform), nil)
+	}
+
+	return nil
+}
+
+// GetProviderPlatform returns the platform of the terraform providers for a container platform, e.g.
+// linux_amd64 for linux/amd64. The variant (e.g. v7 of linux/arm/v7) is dropped.
+func GetProviderPlatform(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s_%s", parts[0], parts[1])
+}
+
+// WarnIfNotNativePlatform logs a warning when the platform isn't the one of the Dagger engine, since
+// the container then runs emulated, which is slower.
+func WarnIfNotNativePlatform(td *terradagger.TD, platform string) {
+	client := td.Engine.GetEngine()
+	if client == nil {
+		return
+	}
+
+	nativePlatform, err := client.DefaultPlatform(td.Ctx)
+	if err != nil {
+		td.Log.Warn(fmt.Sprintf("unable to get the platform of the dagger engine: %s", err))
+		return
+	}
+
+	if string(nativePlatform) != platform {
+		td.Log.Warn(fmt.Sprintf("the container platform %s isn't the native platform of the dagger engine (%s), so it runs emulated", platform, nativePlatform))
+	}
+}
@@ -0,0 +1,32 @@
+package container
+
+import "testing"
+
+func TestValidatePlatform(t *testing.T) {
+	for _, platform := range []string{"linux/amd64", "linux/arm64", "linux/arm/v7"} {
+		if err := ValidatePlatform(platform); err != nil {
+			t.Errorf("ValidatePlatform(%s) error = %v", platform, err)
+		}
+	}
+
+	for _, platform := range []string{"", "amd64", "linux_amd64", "linux/amd64/v8/extra", "Linux/AMD64"} {
+		if err := ValidatePlatform(platform); err == nil {
+			t.Errorf("ValidatePlatform(%s) should fail", platform)
+		}
+	}
+}
+
+func TestGetProviderPlatform(t *testing.T) {
+	tests := map[string]string{
+		"linux/amd64":  "linux_amd64",
+		"darwin/arm64": "darwin_arm64",
+		"linux/arm/v7": "linux_arm",
+		"amd64":        "",
+	}
+
+	for platform, want := range tests {
+		if got := GetProviderPlatform(platform); got != want {
+			t.Errorf("GetProviderPlatform(%s) = %s, want %s", platform, got, want)
+		}
+	}
+}
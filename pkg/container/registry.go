@@ -0,0 +1,124 @@
+package container
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const defaultRegistry = "docker.io"
+
+// RegistryCredential authenticates the pulls of the images of a private registry.
+type RegistryCredential struct {
+	// Address is the registry, e.g. registry.example.com or ghcr.io
+	Address string
+	// Username is the user of the registry.
+	Username string
+	// Password is the password or the token of the user. It's passed to Dagger as a secret.
+	Password string
+}
+
+// Validate checks that the credential has an address, a username and a password.
+func (c *RegistryCredential) Validate() error {
+	if c.Address == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError("the address of the registry credential is empty", nil)
+	}
+
+	if c.Username == "" || c.Password == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the username and the password of the registry credential for %s are required", c.Address), nil)
+	}
+
+	return nil
+}
+
+// GetDefaultDockerConfigFile returns the docker config.json of the host: $DOCKER_CONFIG/config.json, or
+// ~/.docker/config.json
+func GetDefaultDockerConfigFile(homeDir string) string {
+	if dockerConfigDir := os.Getenv("DOCKER_CONFIG"); dockerConfigDir != "" {
+		return filepath.Join(dockerConfigDir, "config.json")
+	}
+
+	return filepath.Join(homeDir, ".docker", "config.json")
+}
+
+// dockerConfig is the part of the docker config.json with the credentials stored in the file. The
+// credentials of the credential helpers (credsStore, credHelpers) aren't read.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// GetRegistryCredentialsFromDockerConfig reads the registry credentials of a docker config.json, sorted
+// by address. The registries without credentials in the file are skipped.
+func GetRegistryCredentialsFromDockerConfig(configFile string) ([]RegistryCredential, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to read the docker config %s", configFile), err)
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the docker config %s isn't valid", configFile), err)
+	}
+
+	var credentials []RegistryCredential
+	for address, auth := range config.Auths {
+		credential := RegistryCredential{
+			Address:  getRegistryHost(address),
+			Username: auth.Username,
+			Password: auth.Password,
+		}
+
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the auth of %s in the docker config %s isn't valid", address, configFile), err)
+			}
+
+			credential.Username, credential.Password, _ = strings.Cut(string(decoded), ":")
+		}
+
+		if credential.Username != "" && credential.Password != "" {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].Address < credentials[j].Address
+	})
+
+	return credentials, nil
+}
+
+// getRegistryHost returns the host of a registry address of the docker config, e.g. docker.io of
+// https://index.docker.io/v1/
+func getRegistryHost(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address, _, _ = strings.Cut(address, "/")
+
+	if address == "index.docker.io" || address == "registry-1.docker.io" {
+		return defaultRegistry
+	}
+
+	return address
+}
+
+// withRegistryAuth adds the registry credentials to the container, before it pulls its image.
+func withRegistryAuth(client *dagger.Client, container *dagger.Container, credentials []RegistryCredential) *dagger.Container {
+	for _, credential := range credentials {
+		secret := setSecret(client, fmt.Sprintf("terradagger-registry-%s", credential.Address), credential.Password)
+		container = container.WithRegistryAuth(credential.Address, credential.Username, secret)
+	}
+
+	return container
+}
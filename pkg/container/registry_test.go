@@ -0,0 +1,66 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetRegistryCredentialsFromDockerConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "dXNlcjpodWItdG9rZW4="},
+    "registry.example.com": {"username": "ci", "password": "registry-token"},
+    "ghcr.io": {}
+  },
+  "credsStore": "desktop"
+}`
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetRegistryCredentialsFromDockerConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RegistryCredential{
+		{Address: "docker.io", Username: "user", Password: "hub-token"},
+		{Address: "registry.example.com", Username: "ci", Password: "registry-token"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRegistryCredentialsFromDockerConfig() = %v, want %v", got, want)
+	}
+
+	if _, err := GetRegistryCredentialsFromDockerConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("GetRegistryCredentialsFromDockerConfig() of a missing file should fail")
+	}
+}
+
+func TestRegistryCredential_Validate(t *testing.T) {
+	if err := (&RegistryCredential{Address: "ghcr.io", Username: "ci", Password: "token"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if err := (&RegistryCredential{Address: "ghcr.io", Username: "ci"}).Validate(); err == nil {
+		t.Error("Validate() without a password should fail")
+	}
+
+	if err := (&RegistryCredential{Username: "ci", Password: "token"}).Validate(); err == nil {
+		t.Error("Validate() without an address should fail")
+	}
+}
+
+func TestGetDefaultDockerConfigFile(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", "")
+	if got := GetDefaultDockerConfigFile("/home/user"); got != "/home/user/.docker/config.json" {
+		t.Errorf("GetDefaultDockerConfigFile() = %s", got)
+	}
+
+	t.Setenv("DOCKER_CONFIG", "/etc/docker")
+	if got := GetDefaultDockerConfigFile("/home/user"); got != "/etc/docker/config.json" {
+		t.Errorf("GetDefaultDockerConfigFile() = %s", got)
+	}
+}
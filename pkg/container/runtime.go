@@ -1,44 +1,73 @@
 package container
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
 	"dagger.io/dagger"
 	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
 )
 
 type runtime struct {
 	container Container
 	td        *terradagger.TD
+	steps     map[*dagger.Container]*recordedStep
+	// interruptible are the containers that run an interruptible command (see
+	// terradagger.BuildInterruptibleCMDWithSH), so they're run gracefully.
+	interruptible map[*dagger.Container]bool
 }
 
+const terraformCLIConfigFileEnvVar = "TF_CLI_CONFIG_FILE"
+
 type Command []string
 
 type Runtime interface {
 	CreateContainer() *dagger.Container
 	OverrideWorkdir(workdir string, container *dagger.Container) *dagger.Container
 	AddCommands(commands []Command, container *dagger.Container) *dagger.Container
+	AddSteps(steps []Step, container *dagger.Container) *dagger.Container
 	RunAndGetStdout(container *dagger.Container) (string, error)
 	ForwardUnixSockets(container *dagger.Container) *dagger.Container
 	AddEnvVars(envVars map[string]string, container *dagger.Container) *dagger.Container
+	AddSecretEnvVars(envVars map[string]string, container *dagger.Container) *dagger.Container
 }
 
 func New(container Container, td *terradagger.TD) Runtime {
 	return &runtime{
-		container: container,
-		td:        td,
+		container:     container,
+		td:            td,
+		steps:         map[*dagger.Container]*recordedStep{},
+		interruptible: map[*dagger.Container]bool{},
 	}
 }
 
 func (r *runtime) CreateContainer() *dagger.Container {
-	containerImageCfg := r.container.GetImageConfig()
-	containerImage := containerImageCfg.GetTerraformContainerImage()
 	mntPathPrefix := r.container.GetMountPathPrefix()
 	mountDir := r.container.GetMountDir(r.td.Engine.GetEngine())
 
-	base := r.td.Engine.GetEngine().Container().From(containerImage).
-		WithMountedDirectory(mntPathPrefix, mountDir)
+	base := r.getImageContainer().WithMountedDirectory(mntPathPrefix, mountDir)
 
 	if r.container.IsPrivateGitSupportEnabled() {
 		base = r.ForwardUnixSockets(base)
+	} else {
+		// The known hosts also apply to the SSH sources cloned without the agent, e.g. with a deploy key.
+		base = r.addSSHKnownHosts(base)
+	}
+
+	base = r.addMounts(base)
+	base = r.addGitHTTPSAuth(base)
+	base = r.addNetworkConfig(base)
+	base = r.addTerraformCLIConfig(base)
+
+	for _, binding := range r.container.GetServiceBindings() {
+		base = base.WithServiceBinding(binding.Alias, binding.Service)
 	}
 
 	if !r.container.IsKeepEntryPoint() {
@@ -57,30 +86,254 @@ func (r *runtime) CreateContainer() *dagger.Container {
 	return base
 }
 
+// getImageContainer returns the container of the image (or the base container, e.g. the toolchain),
+// before the workspace, the mounts and the configuration are added.
+func (r *runtime) getImageContainer() *dagger.Container {
+	if base := r.container.GetBaseContainer(); base != nil {
+		return base
+	}
+
+	client := r.td.Engine.GetEngine()
+
+	return withRegistryAuth(client, client.Container(dagger.ContainerOpts{
+		Platform: dagger.Platform(r.container.GetPlatform()),
+	}), r.container.GetRegistryCredentials()).From(r.container.GetImageConfig().GetTerraformContainerImage())
+}
+
 func (r *runtime) OverrideWorkdir(workdir string, container *dagger.Container) *dagger.Container {
 	return container.WithWorkdir(workdir)
 }
 
 func (r *runtime) AddCommands(commands []Command, container *dagger.Container) *dagger.Container {
-	if len(commands) > 0 {
-		for _, cmd := range commands {
-			container = container.WithExec(cmd)
+	steps := make([]Step, 0, len(commands))
+	for _, cmd := range commands {
+		steps = append(steps, Step{Name: getStepName(cmd), Command: cmd})
+	}
+
+	return r.AddSteps(steps, container)
+}
+
+// AddSteps adds the commands to the container. If a step log is configured, each step is recorded, and
+// it writes its output into the step logs directory, so it's streamed into the log while it runs (unless
+// the entrypoint is kept, as the command is wrapped in sh). The interruptible commands get the
+// interrupts directory, where they watch their interrupt marker.
+func (r *runtime) AddSteps(steps []Step, container *dagger.Container) *dagger.Container {
+	for _, step := range steps {
+		parent := container
+		interruptible := r.interruptible[parent] || terradagger.IsInterruptibleCMD(step.Command)
+		if terradagger.IsInterruptibleCMD(step.Command) {
+			container = r.addInterruptsDir(container)
+		}
+
+		if r.container.GetStepLog() == nil {
+			container = container.WithExec(step.Command)
+		} else {
+			recorded := &recordedStep{
+				Step:   step,
+				parent: parent,
+			}
+
+			if r.container.IsKeepEntryPoint() {
+				container = container.WithExec(step.Command)
+			} else {
+				recorded.logDir = path.Join(stepLogsDir, utils.GetUUID())
+				container = r.addStepLogsDir(container).WithExec(getStepLogCommand(step.Command, recorded.logDir))
+			}
+
+			recorded.container = container
+			r.steps[container] = recorded
+		}
+
+		if interruptible {
+			r.interruptible[container] = true
 		}
 	}
 
 	return container
 }
 
+// RunAndGetStdout runs the container and returns the stdout of its last command. If a step log is
+// configured, each step runs in order, and its output is streamed into the log while it runs. A
+// container with an interruptible command (e.g. apply) is run gracefully: if the terradagger context
+// is cancelled, terraform receives a SIGINT, and it has the grace period to stop (see TD.RunGracefully).
 func (r *runtime) RunAndGetStdout(container *dagger.Container) (string, error) {
-	return container.Stdout(r.td.Ctx)
+	if !r.interruptible[container] {
+		return r.run(r.td.Ctx, container)
+	}
+
+	var stdout string
+	err := r.td.RunGracefully(func(ctx context.Context) error {
+		var err error
+		stdout, err = r.run(ctx, container)
+		return err
+	}, r.interrupt)
+
+	return stdout, err
+}
+
+func (r *runtime) run(ctx context.Context, container *dagger.Container) (string, error) {
+	if r.container.GetStepLog() == nil {
+		return container.Stdout(ctx)
+	}
+
+	return r.runStepByStep(ctx, container)
+}
+
+// addInterruptsDir mounts the interrupts directory, shared by the containers of the engine.
+func (r *runtime) addInterruptsDir(container *dagger.Container) *dagger.Container {
+	return container.WithMountedCache(terradagger.InterruptsDir, r.td.Engine.GetEngine().CacheVolume(terradagger.InterruptsCacheVolume), dagger.ContainerWithMountedCacheOpts{
+		Sharing: dagger.Shared,
+	})
+}
+
+// addStepLogsDir mounts the step logs directory, shared by the containers of the engine.
+func (r *runtime) addStepLogsDir(container *dagger.Container) *dagger.Container {
+	return container.WithMountedCache(stepLogsDir, r.td.Engine.GetEngine().CacheVolume(stepLogsCacheVolume), dagger.ContainerWithMountedCacheOpts{
+		Sharing: dagger.Shared,
+	})
+}
+
+// interrupt creates the interrupt marker of the terradagger session, in a container of the same image,
+// so the interruptible commands that watch it send a SIGINT to terraform.
+func (r *runtime) interrupt(ctx context.Context) error {
+	marker := r.td.GetInterruptMarkerFile()
+
+	_, err := r.addInterruptsDir(r.getImageContainer().WithoutEntrypoint()).
+		WithEnvVariable(cacheBusterEnvVar.Name, time.Now().String()).
+		WithExec(terradagger.BuildCMDWithSH(fmt.Sprintf("touch '%s'", marker))).
+		Sync(ctx)
+
+	return err
+}
+
+func (r *runtime) runStepByStep(ctx context.Context, container *dagger.Container) (string, error) {
+	stepLog := r.container.GetStepLog()
+
+	// Walk back from the target container, to get only the steps that lead to it. The steps that
+	// already ran (and were logged) aren't recorded anymore, so the walk stops there.
+	var chain []*recordedStep
+	for c := container; c != nil; {
+		step, ok := r.steps[c]
+		if !ok {
+			break
+		}
+
+		chain = append([]*recordedStep{step}, chain...)
+		c = step.parent
+	}
+
+	// The steps are forgotten once they run, so the runtime doesn't grow with every command.
+	defer func() {
+		for _, step := range chain {
+			delete(r.steps, step.container)
+		}
+	}()
+
+	if len(chain) == 0 {
+		return container.Stdout(ctx)
+	}
+
+	var stdout string
+	for _, step := range chain {
+		stream := &stepStream{log: stepLog, id: r.td.ID, step: step.Name}
+		stopTailing := r.tailStep(ctx, step, stream)
+
+		out, err := step.container.Stdout(ctx)
+		stopTailing()
+
+		output := StepOutput{
+			ID:     r.td.ID,
+			Step:   step.Name,
+			Stdout: out,
+			Err:    err,
+		}
+
+		if err != nil {
+			var execErr *dagger.ExecError
+			if errors.As(err, &execErr) {
+				output.Stdout = execErr.Stdout
+				output.Stderr = execErr.Stderr
+			}
+
+			stream.finish(output)
+			return "", err
+		}
+
+		output.Stderr, _ = step.container.Stderr(ctx)
+		stream.finish(output)
+
+		stdout = out
+	}
+
+	return stdout, nil
+}
+
+// tailStep reads the output that the step writes into its log directory every poll interval, and
+// writes it into the stream, until the returned function is called. The step must not be written into
+// the stream concurrently, so the returned function waits for the last read.
+func (r *runtime) tailStep(ctx context.Context, step *recordedStep, stream *stepStream) func() {
+	if step.logDir == "" {
+		return func() {}
+	}
+
+	tailCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(stream.log.getPollInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-tailCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			out, err := r.addStepLogsDir(r.getImageContainer().WithoutEntrypoint()).
+				WithEnvVariable(cacheBusterEnvVar.Name, time.Now().String()).
+				WithExec(getStepLogTailCommand(step.logDir, int(stream.stdout), int(stream.stderr))).
+				Stdout(tailCtx)
+			if err != nil {
+				// The step might have finished (and the read been cancelled); what wasn't read is written
+				// once it finishes.
+				continue
+			}
+
+			stdoutChunk, stderrChunk, _ := strings.Cut(out, "\x00")
+			stream.write(stdoutChunk, stderrChunk)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 func (r *runtime) ForwardUnixSockets(container *dagger.Container) *dagger.Container {
 	unixSocketPath := r.td.Engine.GetEngine().Host().UnixSocket(r.container.GetSSHAuthSockEnvVar().Value)
 
-	return container.WithEnvVariable(r.container.GetGitSSHEnvVar().Name, r.container.GetGitSSHEnvVar().Value).
+	container = container.WithEnvVariable(r.container.GetGitSSHEnvVar().Name, r.container.GetGitSSHEnvVar().Value).
 		WithEnvVariable(r.container.GetSSHAuthSockEnvVar().Name, r.container.GetSSHAuthSockEnvVar().Value).
 		WithUnixSocket(r.container.GetSSHAuthSockEnvVar().Value, unixSocketPath)
+
+	return r.addSSHKnownHosts(container)
+}
+
+// addSSHKnownHosts writes the pinned known_hosts file, and points the SSH command of git to it.
+func (r *runtime) addSSHKnownHosts(container *dagger.Container) *dagger.Container {
+	knownHosts := r.container.GetSSHKnownHosts()
+	if knownHosts == "" {
+		return container
+	}
+
+	return container.WithNewFile(r.container.GetSSHKnownHostsPath(), dagger.ContainerWithNewFileOpts{
+		Contents:    knownHosts,
+		Permissions: 0o644,
+	}).WithEnvVariable(r.container.GetGitSSHEnvVar().Name, r.container.GetGitSSHEnvVar().Value)
 }
 
 func (r *runtime) AddEnvVars(envVars map[string]string, container *dagger.Container) *dagger.Container {
@@ -90,3 +343,42 @@ func (r *runtime) AddEnvVars(envVars map[string]string, container *dagger.Contai
 
 	return container
 }
+
+// AddSecretEnvVars adds the env vars as Dagger secrets, so their values aren't stored in the cache,
+// nor shown in the logs.
+func (r *runtime) AddSecretEnvVars(envVars map[string]string, container *dagger.Container) *dagger.Container {
+	for k, v := range envVars {
+		container = container.WithSecretVariable(k, setSecret(r.td.Engine.GetEngine(), k, v))
+	}
+
+	return container
+}
+
+// setSecret creates a Dagger secret. The secrets are stored by name in the session, and the modules run
+// concurrently on the same session, so the name gets a hash of the value: two modules with different
+// values for the same secret (e.g. AWS_SECRET_ACCESS_KEY of two accounts) don't overwrite each other.
+func setSecret(client *dagger.Client, name, value string) *dagger.Secret {
+	return client.SetSecret(getSecretName(name, value), value)
+}
+
+// getSecretName returns the name of the secret, suffixed with a hash of its value.
+func getSecretName(name, value string) string {
+	hash := sha256.Sum256([]byte(name + "\x00" + value))
+
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(hash[:8]))
+}
+
+// addTerraformCLIConfig writes the terraform CLI configuration, and points TF_CLI_CONFIG_FILE to it.
+func (r *runtime) addTerraformCLIConfig(container *dagger.Container) *dagger.Container {
+	cliConfig := r.container.GetTerraformCLIConfig()
+	if cliConfig == "" {
+		return container
+	}
+
+	cliConfigPath := path.Join(HomeDir, ".terraformrc")
+
+	return container.WithNewFile(cliConfigPath, dagger.ContainerWithNewFileOpts{
+		Contents:    cliConfig,
+		Permissions: 0o644,
+	}).WithEnvVariable(terraformCLIConfigFileEnvVar, cliConfigPath)
+}
@@ -0,0 +1,21 @@
+package container
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSecretName(t *testing.T) {
+	name := getSecretName("AWS_SECRET_ACCESS_KEY", "secret-of-account-a")
+	if !strings.HasPrefix(name, "AWS_SECRET_ACCESS_KEY-") || strings.Contains(name, "secret-of-account-a") {
+		t.Errorf("getSecretName() = %s", name)
+	}
+
+	if other := getSecretName("AWS_SECRET_ACCESS_KEY", "secret-of-account-b"); other == name {
+		t.Errorf("getSecretName() of different values = %s, want different names", other)
+	}
+
+	if again := getSecretName("AWS_SECRET_ACCESS_KEY", "secret-of-account-a"); again != name {
+		t.Errorf("getSecretName() of the same value = %s, want %s", again, name)
+	}
+}
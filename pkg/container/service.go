@@ -0,0 +1,237 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+const (
+	defaultServiceReadinessTimeout  = 60 * time.Second
+	defaultServiceReadinessInterval = 2 * time.Second
+)
+
+// startedServices are the services started in each terradagger session, keyed by the Dagger client of
+// the session (shared by the TDs of the orchestrated jobs), and by their spec, so each one is started
+// (and checked for readiness) only once per session.
+var (
+	startedServicesMu sync.Mutex
+	startedServices   = map[*dagger.Client]map[string]*startedService{}
+)
+
+// startedService is a service that's starting, or started. ready is closed once it's started (or it
+// failed to start).
+type startedService struct {
+	ready   chan struct{}
+	binding ServiceBinding
+	err     error
+}
+
+// Service is a sidecar (e.g. LocalStack, a database, or a mock API) that runs as a Dagger service, and
+// that's reachable from the container through its alias, used as the hostname.
+type Service struct {
+	// Alias is the hostname of the service, as seen from the container.
+	Alias string
+	// Image is the container image of the service, e.g. postgres:16-alpine
+	Image string
+	// Ports are the ports exposed by the service. The service is ready once they accept connections.
+	Ports []int
+	// Env are the environment variables of the service.
+	Env map[string]string
+	// Args is the command of the service. If it's empty, the default command of the image is used.
+	Args []string
+	// ReadinessCommand, if set, runs in a container of the same image (bound to the service) until it
+	// succeeds, e.g. []string{"pg_isready", "-h", "postgres"}.
+	ReadinessCommand []string
+	// ReadinessTimeout is the maximum time to wait for the ReadinessCommand to succeed. Defaults to 60s.
+	ReadinessTimeout time.Duration
+}
+
+// Validate checks that the service has an alias and an image.
+func (s *Service) Validate() error {
+	if s.Alias == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError("the alias of the service is empty", nil)
+	}
+
+	if s.Image == "" {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the image of the service %s is empty", s.Alias), nil)
+	}
+
+	return nil
+}
+
+// getServiceContainer returns the container of the service, with its env vars and exposed ports.
+func (s *Service) getServiceContainer(client *dagger.Client) *dagger.Container {
+	serviceContainer := client.Container().From(s.Image)
+
+	keys := make([]string, 0, len(s.Env))
+	for key := range s.Env {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		serviceContainer = serviceContainer.WithEnvVariable(key, s.Env[key])
+	}
+
+	for _, port := range s.Ports {
+		serviceContainer = serviceContainer.WithExposedPort(port)
+	}
+
+	// Without args, the service runs the default command of the image.
+	if len(s.Args) > 0 {
+		serviceContainer = serviceContainer.WithExec(s.Args)
+	}
+
+	return serviceContainer
+}
+
+// getKey identifies the spec of the service. The maps are printed sorted by key.
+func (s *Service) getKey() string {
+	return fmt.Sprintf("%+v", *s)
+}
+
+// StartService starts the service, and waits until it's ready: its ports accept connections, and its
+// readiness command (if any) succeeds. A service with the same spec is started only once per
+// terradagger session, and it's stopped when the session is closed.
+func StartService(td *terradagger.TD, service Service) (ServiceBinding, error) {
+	if err := service.Validate(); err != nil {
+		return ServiceBinding{}, err
+	}
+
+	client := td.Engine.GetEngine()
+	if client == nil {
+		return ServiceBinding{}, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the dagger engine must be started before the service %s", service.Alias), nil)
+	}
+
+	key := service.getKey()
+
+	startedServicesMu.Lock()
+	sessionServices, ok := startedServices[client]
+	if !ok {
+		sessionServices = map[string]*startedService{}
+		startedServices[client] = sessionServices
+		td.OnClose(func() error {
+			return stopServices(client)
+		})
+	}
+
+	started, ok := sessionServices[key]
+	if ok {
+		startedServicesMu.Unlock()
+		<-started.ready
+
+		return started.binding, started.err
+	}
+
+	started = &startedService{ready: make(chan struct{})}
+	sessionServices[key] = started
+	startedServicesMu.Unlock()
+
+	started.binding, started.err = startService(td, client, service)
+	close(started.ready)
+
+	// A service that failed to start is started again by the next command.
+	if started.err != nil {
+		startedServicesMu.Lock()
+		delete(sessionServices, key)
+		startedServicesMu.Unlock()
+	}
+
+	return started.binding, started.err
+}
+
+func startService(td *terradagger.TD, client *dagger.Client, service Service) (ServiceBinding, error) {
+	daggerService, err := service.getServiceContainer(client).AsService().Start(td.Ctx)
+	if err != nil {
+		return ServiceBinding{}, erroer.NewErrTerraDaggerServiceNotReadyError(service.Alias, "unable to start the service", err)
+	}
+
+	if len(service.ReadinessCommand) > 0 {
+		if err := waitForService(td, client, &service, daggerService); err != nil {
+			return ServiceBinding{}, err
+		}
+	}
+
+	td.Log.Info(fmt.Sprintf("service %s (%s) is ready", service.Alias, service.Image))
+
+	return ServiceBinding{Alias: service.Alias, Service: daggerService}, nil
+}
+
+// stopServices stops the services started in the terradagger session of the client. Its context is
+// already cancelled, so they're stopped with a new one.
+func stopServices(client *dagger.Client) error {
+	startedServicesMu.Lock()
+	sessionServices := startedServices[client]
+	delete(startedServices, client)
+	startedServicesMu.Unlock()
+
+	var errs []error
+	for _, started := range sessionServices {
+		<-started.ready
+		if started.err != nil {
+			continue
+		}
+
+		if _, err := started.binding.Service.Stop(context.Background()); err != nil {
+			errs = append(errs, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("unable to stop the service %s", started.binding.Alias), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// StartServices starts the services, and returns their bindings.
+func StartServices(td *terradagger.TD, services []Service) ([]ServiceBinding, error) {
+	bindings := make([]ServiceBinding, 0, len(services))
+	for _, service := range services {
+		binding, err := StartService(td, service)
+		if err != nil {
+			return nil, err
+		}
+
+		bindings = append(bindings, binding)
+	}
+
+	return bindings, nil
+}
+
+// waitForService runs the readiness command until it succeeds, or the readiness timeout expires.
+func waitForService(td *terradagger.TD, client *dagger.Client, service *Service, daggerService *dagger.Service) error {
+	timeout := service.ReadinessTimeout
+	if timeout <= 0 {
+		timeout = defaultServiceReadinessTimeout
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		// The cache buster forces the probe to run every time, instead of reusing a cached result.
+		_, err := client.Container().
+			From(service.Image).
+			WithServiceBinding(service.Alias, daggerService).
+			WithEnvVariable(cacheBusterEnvVar.Name, time.Now().String()).
+			WithExec(service.ReadinessCommand, dagger.ContainerWithExecOpts{SkipEntrypoint: true}).
+			Sync(td.Ctx)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return erroer.NewErrTerraDaggerServiceNotReadyError(service.Alias, fmt.Sprintf("the readiness command didn't succeed after %s", timeout), err)
+		}
+
+		select {
+		case <-td.Ctx.Done():
+			return erroer.NewErrTerraDaggerServiceNotReadyError(service.Alias, "cancelled while waiting for the service", td.Ctx.Err())
+		case <-time.After(defaultServiceReadinessInterval):
+		}
+	}
+}
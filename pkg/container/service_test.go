@@ -0,0 +1,54 @@
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+func TestService_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		wantErr bool
+	}{
+		{"valid", Service{Alias: "postgres", Image: "postgres:16-alpine", Ports: []int{5432}}, false},
+		{"missing alias", Service{Image: "postgres:16-alpine"}, true},
+		{"missing image", Service{Alias: "postgres"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.service.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Service.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStartServices_EngineNotStarted(t *testing.T) {
+	td := terradagger.New(context.Background(), &terradagger.Options{})
+
+	bindings, err := StartServices(td, nil)
+	if err != nil || len(bindings) != 0 {
+		t.Errorf("StartServices() without services = %v, %v, want no bindings and no error", bindings, err)
+	}
+
+	if _, err := StartServices(td, []Service{{Alias: "localstack", Image: "localstack/localstack"}}); err == nil {
+		t.Errorf("StartServices() expected an error when the engine isn't started")
+	}
+}
+
+func TestService_getKey(t *testing.T) {
+	service := Service{Alias: "postgres", Image: "postgres:16-alpine", Ports: []int{5432}, Env: map[string]string{"B": "2", "A": "1"}}
+	same := Service{Alias: "postgres", Image: "postgres:16-alpine", Ports: []int{5432}, Env: map[string]string{"A": "1", "B": "2"}}
+	other := Service{Alias: "postgres", Image: "postgres:15-alpine", Ports: []int{5432}}
+
+	if service.getKey() != same.getKey() {
+		t.Errorf("getKey() differs for the same spec: %s, %s", service.getKey(), same.getKey())
+	}
+
+	if service.getKey() == other.getKey() {
+		t.Errorf("getKey() is the same for different specs: %s", service.getKey())
+	}
+}
@@ -0,0 +1,220 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"dagger.io/dagger"
+)
+
+// Step is a named command that runs in the container, e.g. "terraform init".
+type Step struct {
+	Name    string
+	Command Command
+}
+
+// StepOutput is the output of a single step, tagged with the step name and the terradagger ID.
+type StepOutput struct {
+	ID     string
+	Step   string
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// StepLog receives the output of each step (e.g. terraform init, then terraform apply) while it runs,
+// instead of only the stdout of the last one when the whole chain finishes. The output of a running
+// step is read every PollInterval (from files in a cache volume that the step writes into), so a long
+// apply shows its progress. Lines written into Stdout and Stderr are prefixed with the terradagger ID
+// and the step name. To persist the logs, pass a file (or an io.MultiWriter).
+// Each step writes its output into its own directory, so the steps with a step log aren't cached between
+// runs.
+type StepLog struct {
+	// Stdout receives the standard output of each step, line by line, while it runs
+	Stdout io.Writer
+	// Stderr receives the standard error of each step, line by line, while it runs
+	Stderr io.Writer
+	// OnOutput is called with the new lines of the stdout and the stderr of a step, while it runs, after
+	// they're written into the writers
+	OnOutput func(output StepOutput)
+	// OnStep is called with the whole output of each step, once it finishes
+	OnStep func(output StepOutput)
+	// PollInterval is how often the output of a running step is read. Defaults to 2s
+	PollInterval time.Duration
+}
+
+func (s *StepLog) getPollInterval() time.Duration {
+	if s.PollInterval <= 0 {
+		return defaultStepLogPollInterval
+	}
+
+	return s.PollInterval
+}
+
+const (
+	defaultStepLogPollInterval = 2 * time.Second
+
+	// stepLogsDir is the directory of the containers where the steps write their output, so it's read
+	// while they run. It's a cache volume (stepLogsCacheVolume) shared by the containers of the engine.
+	stepLogsDir         = "/terradagger/steps"
+	stepLogsCacheVolume = "terradagger-step-logs"
+)
+
+// recordedStep links a step with the container it produces, and the container it was added to. The
+// step writes its output into logDir.
+type recordedStep struct {
+	Step
+	parent    *dagger.Container
+	container *dagger.Container
+	logDir    string
+}
+
+// getStepLogCommand wraps the command, so it writes its stdout and stderr into the log directory while
+// it runs. Once it exits, they're written into the stdout and stderr of the container, as without the
+// step log, and the log directory is removed.
+func getStepLogCommand(cmd Command, logDir string) Command {
+	script := fmt.Sprintf(`d='%s'; mkdir -p "$d"; "$@" >"$d/stdout" 2>"$d/stderr"; status=$?; `, logDir) +
+		`cat "$d/stdout"; cat "$d/stderr" >&2; rm -rf "$d"; exit $status`
+
+	return append(Command{"sh", "-c", script, "sh"}, cmd...)
+}
+
+// getStepLogTailCommand prints the stdout and the stderr written by a running step, from the given byte
+// offsets, separated by a NUL byte. The files don't exist before the step starts, nor after it ends.
+func getStepLogTailCommand(logDir string, stdoutOffset, stderrOffset int) Command {
+	return Command{"sh", "-c", fmt.Sprintf(`tail -c +%d '%s/stdout' 2>/dev/null; printf '\000'; tail -c +%d '%s/stderr' 2>/dev/null; true`,
+		stdoutOffset+1, logDir, stderrOffset+1, logDir)}
+}
+
+// stepStream receives the output of a running step in chunks, and emits it line by line.
+type stepStream struct {
+	log    *StepLog
+	id     string
+	step   string
+	stdout stepStreamOffset
+	stderr stepStreamOffset
+}
+
+// stepStreamOffset is how much of a stream was emitted (in bytes). Only complete lines are emitted while
+// the step runs: the last incomplete line (which might end in the middle of a rune) is read again by the
+// next poll.
+type stepStreamOffset int
+
+// add returns the complete lines of a chunk read from the offset, and moves the offset past them.
+func (o *stepStreamOffset) add(chunk string) string {
+	idx := strings.LastIndex(chunk, "\n")
+	if idx < 0 {
+		return ""
+	}
+
+	*o += stepStreamOffset(idx + 1)
+	return chunk[:idx+1]
+}
+
+// getRemaining returns what wasn't emitted yet of the whole output, and moves the offset to its end.
+func (o *stepStreamOffset) getRemaining(output string) string {
+	offset := int(*o)
+	*o = stepStreamOffset(len(output))
+
+	if offset >= len(output) {
+		return ""
+	}
+
+	return output[offset:]
+}
+
+// write emits the complete lines of the chunks, read from the current offsets.
+func (s *stepStream) write(stdoutChunk, stderrChunk string) {
+	s.emitLines(s.stdout.add(stdoutChunk), s.stderr.add(stderrChunk))
+}
+
+// finish emits what wasn't streamed of the whole output of the step, and then the whole output.
+func (s *stepStream) finish(output StepOutput) {
+	s.emitLines(s.stdout.getRemaining(output.Stdout), s.stderr.getRemaining(output.Stderr))
+
+	if s.log.OnStep != nil {
+		s.log.OnStep(output)
+	}
+}
+
+func (s *stepStream) emitLines(stdout, stderr string) {
+	if stdout == "" && stderr == "" {
+		return
+	}
+
+	tag := fmt.Sprintf("[%s][%s] ", s.id, s.step)
+	writeTagged(s.log.Stdout, tag, stdout)
+	writeTagged(s.log.Stderr, tag, stderr)
+
+	if s.log.OnOutput != nil {
+		s.log.OnOutput(StepOutput{ID: s.id, Step: s.step, Stdout: stdout, Stderr: stderr})
+	}
+}
+
+// NewFileStepLog returns a step log that writes the stdout and stderr of every step into the given file,
+// and also into the standard output of the host. The returned closer closes the file.
+func NewFileStepLog(path string) (*StepLog, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open the log file %s: %w", path, err)
+	}
+
+	w := &syncWriter{w: io.MultiWriter(os.Stdout, f)}
+
+	return &StepLog{
+		Stdout: w,
+		Stderr: w,
+	}, f, nil
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
+
+// getStepName derives a readable name from a command, e.g. sh -c "terraform init -upgrade" -> terraform init.
+func getStepName(cmd Command) string {
+	cmdStr := strings.Join(cmd, " ")
+	if len(cmd) == 3 && cmd[1] == "-c" {
+		cmdStr = cmd[2]
+	}
+
+	fields := strings.Fields(cmdStr)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// writeTagged writes the content line by line into w, prefixed with the tag.
+func writeTagged(w io.Writer, tag, content string) {
+	if w == nil || content == "" {
+		return
+	}
+
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		buf.WriteString(tag)
+		buf.WriteString(scanner.Text())
+		buf.WriteString("\n")
+	}
+
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,128 @@
+package container
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetStepName(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		want string
+	}{
+		{"shell command", Command{"sh", "-c", "terraform init -upgrade"}, "terraform init"},
+		{"short shell command", Command{"sh", "-c", "terraform"}, "terraform"},
+		{"plain command", Command{"terraform", "plan", "-out=plan"}, "terraform plan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getStepName(tt.cmd); got != tt.want {
+				t.Errorf("getStepName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepStream(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var outputs, received []StepOutput
+
+	s := &stepStream{
+		log: &StepLog{
+			Stdout: &stdout,
+			Stderr: &stderr,
+			OnOutput: func(output StepOutput) {
+				outputs = append(outputs, output)
+			},
+			OnStep: func(output StepOutput) {
+				received = append(received, output)
+			},
+		},
+		id:   "abc",
+		step: "terraform apply",
+	}
+
+	// Only the complete lines are emitted while the step runs; the rest is read again by the next poll.
+	s.write("line 1\nline", "")
+	if want := "[abc][terraform apply] line 1\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	if s.stdout != 7 || s.stderr != 0 {
+		t.Errorf("offsets = %d, %d, want 7, 0", s.stdout, s.stderr)
+	}
+
+	s.write("line 2\n", "warning\n")
+	s.finish(StepOutput{ID: "abc", Step: "terraform apply", Stdout: "line 1\nline 2\nline 3", Stderr: "warning\nerror"})
+
+	want := "[abc][terraform apply] line 1\n[abc][terraform apply] line 2\n[abc][terraform apply] line 3\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	if want := "[abc][terraform apply] warning\n[abc][terraform apply] error\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+
+	var streamed string
+	for _, output := range outputs {
+		streamed += output.Stdout
+	}
+
+	if streamed != "line 1\nline 2\nline 3" {
+		t.Errorf("OnOutput received %q, want the whole stdout", streamed)
+	}
+
+	if len(received) != 1 || received[0].Stdout != "line 1\nline 2\nline 3" {
+		t.Errorf("OnStep received %v, want the whole output of terraform apply", received)
+	}
+}
+
+func TestGetStepLogCommand(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "step")
+	cmd := getStepLogCommand(Command{"sh", "-c", "echo out; echo err >&2; exit 3"}, logDir)
+
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Run() error = %v, want the exit code 3 of the command", err)
+	}
+
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("output = %q, %q, want the output of the command", stdout.String(), stderr.String())
+	}
+
+	if _, err := os.Stat(logDir); !os.IsNotExist(err) {
+		t.Errorf("the log directory %s wasn't removed", logDir)
+	}
+}
+
+func TestGetStepLogTailCommand(t *testing.T) {
+	logDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(logDir, "stdout"), []byte("line 1\nline 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := getStepLogTailCommand(logDir, 7, 0)
+
+	out, err := exec.Command(cmd[0], cmd[1:]...).Output()
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	// The stderr file doesn't exist (yet).
+	if want := "line 2\n\x00"; string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
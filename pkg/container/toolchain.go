@@ -0,0 +1,265 @@
+package container
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+const (
+	ToolchainBaseDebian = "debian"
+	ToolchainBaseAlpine = "alpine"
+
+	// defaultToolchainDebianImage is pinned by digest. The Alpine base (and any other one that isn't) is
+	// pinned by digest in the image lock file when the toolchain is built, see BuildToolchain.
+	defaultToolchainDebianImage = "debian:bookworm-slim@sha256:2bc5c236e9b262645a323e9088dfa3bb1ecb16cc75811daf40a23a824d665be9"
+	defaultToolchainAlpineImage = "alpine:3.19"
+	defaultToolchainPlatform    = "linux/amd64"
+
+	toolchainBinDir      = "/usr/local/bin"
+	toolchainDownloadDir = "/tmp/terradagger-toolchain"
+
+	defaultTerraformURL  = "https://releases.hashicorp.com/terraform/{version}/terraform_{version}_{os}_{arch}.zip"
+	defaultTerragruntURL = "https://github.com/gruntwork-io/terragrunt/releases/download/v{version}/terragrunt_{os}_{arch}"
+	defaultTFLintURL     = "https://github.com/terraform-linters/tflint/releases/download/v{version}/tflint_{os}_{arch}.zip"
+)
+
+// sha256Regex matches a SHA256 checksum, hex encoded.
+var sha256Regex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// packageRegex matches a package of the distribution, optionally pinned to a version, e.g. jq or
+// jq=1.6-2.1 (apt), or jq=1.7.1-r0 (apk).
+var packageRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9+._-]*(=[A-Za-z0-9.+~:_-]+)?$`)
+
+// toolchainBasePackages are the packages installed in every toolchain image: git and ssh for the
+// module sources, and unzip for the release archives.
+var toolchainBasePackages = []string{"ca-certificates", "git", "openssh-client", "unzip"}
+
+// ToolchainTool is a pinned version of a tool of the toolchain image.
+type ToolchainTool struct {
+	// Version is the version of the tool, without the v prefix, e.g. 1.7.5
+	Version string
+	// URL is where the tool is downloaded from, e.g. an internal mirror. It can use the {version}, {os}
+	// and {arch} placeholders. It's a zip archive if it ends in .zip, or the binary otherwise. Defaults
+	// to the official release.
+	URL string
+	// SHA256 is the checksum of the downloaded file (the archive, or the binary).
+	SHA256 string
+}
+
+// Toolchain is a reproducible image, built from a base image plus pinned versions of terraform,
+// terragrunt and tflint. The downloads are verified by checksum, and the layers are cached by Dagger,
+// so every stage of a pipeline that uses the same toolchain gets the same image.
+type Toolchain struct {
+	// Base is the distribution of the base image: ToolchainBaseDebian (default) or ToolchainBaseAlpine.
+	Base string
+	// BaseImage overrides the base image, e.g. a mirror of debian:bookworm-slim. It must be of the Base distribution.
+	BaseImage string
+	// Platform is the platform of the binaries, e.g. linux/arm64. Defaults to linux/amd64.
+	Platform string
+	// Terraform, Terragrunt and TFLint are the tools installed in /usr/local/bin. The ones not set aren't installed.
+	Terraform  *ToolchainTool
+	Terragrunt *ToolchainTool
+	TFLint     *ToolchainTool
+	// Packages are extra packages of the distribution, e.g. jq or python3. A package can be pinned to a
+	// version with name=version, e.g. jq=1.6-2.1. The base packages (e.g. git) can be pinned the same way.
+	Packages []string
+	// Scripts are shell scripts that run last, e.g. to install a plugin.
+	Scripts []string
+}
+
+// Validate checks the base of the toolchain, and that its tools are pinned to a version and a checksum.
+func (t *Toolchain) Validate() error {
+	if t.Base != "" && t.Base != ToolchainBaseDebian && t.Base != ToolchainBaseAlpine {
+		return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the toolchain base %s isn't supported, it should be %s or %s", t.Base, ToolchainBaseDebian, ToolchainBaseAlpine), nil)
+	}
+
+	if _, _, err := t.getOSAndArch(); err != nil {
+		return err
+	}
+
+	for _, pkg := range t.Packages {
+		if !packageRegex.MatchString(pkg) {
+			return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the package %s of the toolchain isn't valid, it should be like name or name=version", pkg), nil)
+		}
+	}
+
+	for _, spec := range t.getTools() {
+		if spec.tool.Version == "" {
+			return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the version of %s in the toolchain is empty", spec.name), nil)
+		}
+
+		if !sha256Regex.MatchString(spec.tool.SHA256) {
+			return erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the SHA256 checksum of %s in the toolchain isn't valid", spec.name), nil)
+		}
+	}
+
+	return nil
+}
+
+type toolchainToolSpec struct {
+	name       string
+	defaultURL string
+	tool       *ToolchainTool
+}
+
+// getTools returns the tools that are set, in a fixed order, so the layers are the same in every build.
+func (t *Toolchain) getTools() []toolchainToolSpec {
+	var tools []toolchainToolSpec
+	for _, spec := range []toolchainToolSpec{
+		{"terraform", defaultTerraformURL, t.Terraform},
+		{"terragrunt", defaultTerragruntURL, t.Terragrunt},
+		{"tflint", defaultTFLintURL, t.TFLint},
+	} {
+		if spec.tool != nil {
+			tools = append(tools, spec)
+		}
+	}
+
+	return tools
+}
+
+func (t *Toolchain) getBaseImage() string {
+	if t.BaseImage != "" {
+		return t.BaseImage
+	}
+
+	if t.Base == ToolchainBaseAlpine {
+		return defaultToolchainAlpineImage
+	}
+
+	return defaultToolchainDebianImage
+}
+
+func (t *Toolchain) getOSAndArch() (string, string, error) {
+	platform := t.Platform
+	if platform == "" {
+		platform = defaultToolchainPlatform
+	}
+
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the toolchain platform %s isn't valid, it should be like linux/amd64", platform), nil)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// getPackages returns the base packages followed by the extra ones. A base package that's also an
+// extra one (e.g. pinned to a version) is only installed as the extra one.
+func (t *Toolchain) getPackages() []string {
+	extra := map[string]bool{}
+	for _, pkg := range t.Packages {
+		name, _, _ := strings.Cut(pkg, "=")
+		extra[name] = true
+	}
+
+	var packages []string
+	for _, pkg := range toolchainBasePackages {
+		if !extra[pkg] {
+			packages = append(packages, pkg)
+		}
+	}
+
+	return append(packages, t.Packages...)
+}
+
+// getPackagesCommand returns the command that installs the packages of the base distribution.
+func (t *Toolchain) getPackagesCommand() Command {
+	packages := strings.Join(t.getPackages(), " ")
+	if t.Base == ToolchainBaseAlpine {
+		return Command{"sh", "-c", fmt.Sprintf("apk add --no-cache %s", packages)}
+	}
+
+	return Command{"sh", "-c", fmt.Sprintf("apt-get update && apt-get install -y --no-install-recommends %s && rm -rf /var/lib/apt/lists/*", packages)}
+}
+
+// getURL returns the download URL of the tool, with its placeholders replaced.
+func (tool *ToolchainTool) getURL(defaultURL, toolOS, arch string) string {
+	url := tool.URL
+	if url == "" {
+		url = defaultURL
+	}
+
+	return strings.NewReplacer("{version}", tool.Version, "{os}", toolOS, "{arch}", arch).Replace(url)
+}
+
+// getToolInstallCommand returns the command that verifies the checksum of the downloaded file, and installs
+// the binary in /usr/local/bin.
+func getToolInstallCommand(name, url, downloadedFile, sha256 string) Command {
+	verify := fmt.Sprintf("echo '%s  %s' | sha256sum -c -", sha256, downloadedFile)
+
+	var install string
+	if strings.HasSuffix(url, ".zip") {
+		install = fmt.Sprintf("unzip -o -q %s %s -d %s && chmod 0755 %s", downloadedFile, name, toolchainBinDir, path.Join(toolchainBinDir, name))
+	} else {
+		install = fmt.Sprintf("install -m 0755 %s %s", downloadedFile, path.Join(toolchainBinDir, name))
+	}
+
+	return Command{"sh", "-c", fmt.Sprintf("%s && %s && rm -f %s", verify, install, downloadedFile)}
+}
+
+// ToolchainBuildOptions are the options to pull the base image of the toolchain.
+type ToolchainBuildOptions struct {
+	// ImageLockFile is the lock file that pins the base image by digest, see PinImage.
+	ImageLockFile string
+	// StrictImageDigests fails the build if the base image doesn't match the digest of the lock file.
+	StrictImageDigests bool
+	// RegistryCredentials authenticate the pull of the base image, e.g. from a private mirror.
+	RegistryCredentials []RegistryCredential
+}
+
+// BuildToolchain returns the container of the toolchain image. It's lazy: the image is built (or taken
+// from the Dagger cache) when the first command runs, and a download whose checksum doesn't match
+// fails the build. A base image that isn't pinned by digest is pinned with the image lock file.
+func BuildToolchain(td *terradagger.TD, toolchain *Toolchain, options ToolchainBuildOptions) (*dagger.Container, error) {
+	if err := toolchain.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := td.Engine.GetEngine()
+	if client == nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError("the dagger engine must be started before building the toolchain", nil)
+	}
+
+	toolOS, arch, _ := toolchain.getOSAndArch()
+	platform := fmt.Sprintf("%s/%s", toolOS, arch)
+
+	baseImage := toolchain.getBaseImage()
+	if options.ImageLockFile != "" {
+		pinnedImage, err := PinImage(td, baseImage, platform, options.ImageLockFile, options.StrictImageDigests, options.RegistryCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		baseImage = pinnedImage
+	}
+
+	td.Log.Info(fmt.Sprintf("building the toolchain image from %s", baseImage))
+
+	toolchainContainer := withRegistryAuth(client, client.Container(dagger.ContainerOpts{Platform: dagger.Platform(platform)}), options.RegistryCredentials).
+		From(baseImage).
+		WithExec(toolchain.getPackagesCommand(), dagger.ContainerWithExecOpts{SkipEntrypoint: true})
+
+	for _, tool := range toolchain.getTools() {
+		url := tool.tool.getURL(tool.defaultURL, toolOS, arch)
+		downloadedFile := path.Join(toolchainDownloadDir, path.Base(url))
+
+		td.Log.Info(fmt.Sprintf("adding %s %s to the toolchain image from %s", tool.name, tool.tool.Version, url))
+
+		toolchainContainer = toolchainContainer.
+			WithFile(downloadedFile, client.HTTP(url)).
+			WithExec(getToolInstallCommand(tool.name, url, downloadedFile, tool.tool.SHA256), dagger.ContainerWithExecOpts{SkipEntrypoint: true})
+	}
+
+	for _, script := range toolchain.Scripts {
+		toolchainContainer = toolchainContainer.WithExec([]string{"sh", "-c", script}, dagger.ContainerWithExecOpts{SkipEntrypoint: true})
+	}
+
+	return toolchainContainer, nil
+}
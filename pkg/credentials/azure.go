@@ -0,0 +1,54 @@
+package credentials
+
+import (
+	"os"
+	"path"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// AzureServicePrincipal injects the ARM_* env vars of a service principal of the host, authenticated
+// with a client secret (ARM_CLIENT_SECRET), or with a client certificate (ARM_CLIENT_CERTIFICATE_PATH).
+type AzureServicePrincipal struct{}
+
+func (p *AzureServicePrincipal) GetName() string {
+	return "azure-service-principal"
+}
+
+func (p *AzureServicePrincipal) Resolve() (*Credentials, error) {
+	credentials := newCredentials(p.GetName())
+	addEnvVars(credentials.EnvVars, "ARM_CLIENT_ID", "ARM_TENANT_ID", "ARM_SUBSCRIPTION_ID", "ARM_ENVIRONMENT")
+
+	for _, key := range []string{"ARM_CLIENT_ID", "ARM_TENANT_ID", "ARM_SUBSCRIPTION_ID"} {
+		if credentials.EnvVars[key] == "" {
+			return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), key+" must be set", nil)
+		}
+	}
+
+	addEnvVars(credentials.SecretEnvVars, "ARM_CLIENT_SECRET", "ARM_CLIENT_CERTIFICATE_PASSWORD")
+
+	certificatePath := os.Getenv("ARM_CLIENT_CERTIFICATE_PATH")
+	if certificatePath == "" {
+		if credentials.SecretEnvVars["ARM_CLIENT_SECRET"] == "" {
+			return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "ARM_CLIENT_SECRET or ARM_CLIENT_CERTIFICATE_PATH must be set", nil)
+		}
+
+		return credentials, nil
+	}
+
+	mount := container.Mount{
+		HostPath:      certificatePath,
+		ContainerPath: path.Join(secretsDir, "azure-client-certificate"),
+		Secret:        true,
+	}
+
+	if err := mount.Validate(); err != nil {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the client certificate doesn't exist", err)
+	}
+
+	credentials.EnvVars["ARM_CLIENT_CERTIFICATE_PATH"] = mount.ContainerPath
+	credentials.Mounts = append(credentials.Mounts, mount)
+
+	return credentials, nil
+}
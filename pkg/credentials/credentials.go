@@ -0,0 +1,99 @@
+// Package credentials resolves cloud credentials (AWS, GCP, Azure) in the host, and returns the
+// minimal set of env vars and files to inject into the terraform container, as secrets.
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+)
+
+// secretsDir is the directory of the container where the credential files are mounted.
+const secretsDir = "/var/run/secrets/terradagger"
+
+// Provider resolves the credentials of a cloud in the host.
+type Provider interface {
+	// GetName returns the name of the provider, e.g. aws-static-keys
+	GetName() string
+	// Resolve returns the credentials to inject into the container, or an
+	// erroer.ErrCredentialsNotFoundError if they aren't available in the host.
+	Resolve() (*Credentials, error)
+}
+
+// Credentials are the env vars and files injected into the container.
+type Credentials struct {
+	// Provider is the name of the provider that resolved them.
+	Provider string
+	// EnvVars are the env vars that aren't sensitive, e.g. AWS_REGION
+	EnvVars map[string]string
+	// SecretEnvVars are the env vars injected as Dagger secrets, e.g. AWS_SECRET_ACCESS_KEY
+	SecretEnvVars map[string]string
+	// Mounts are the host files mounted into the container (as secrets), e.g. a service account key.
+	Mounts []container.Mount
+}
+
+func newCredentials(provider string) *Credentials {
+	return &Credentials{
+		Provider:      provider,
+		EnvVars:       map[string]string{},
+		SecretEnvVars: map[string]string{},
+	}
+}
+
+// GetSummary returns what's injected, without the secret values, e.g.
+// aws-static-keys: AWS_REGION=us-east-1, AWS_ACCESS_KEY_ID=****, AWS_SECRET_ACCESS_KEY=****
+func (c *Credentials) GetSummary() string {
+	var items []string
+	for _, key := range getSortedKeys(c.EnvVars) {
+		items = append(items, fmt.Sprintf("%s=%s", key, c.EnvVars[key]))
+	}
+
+	for _, key := range getSortedKeys(c.SecretEnvVars) {
+		items = append(items, fmt.Sprintf("%s=****", key))
+	}
+
+	for _, mount := range c.Mounts {
+		items = append(items, fmt.Sprintf("file %s", mount.ContainerPath))
+	}
+
+	return fmt.Sprintf("%s: %s", c.Provider, strings.Join(items, ", "))
+}
+
+// Resolve resolves the credentials of all the providers, in order. It fails at the first provider
+// whose credentials aren't available.
+func Resolve(providers []Provider) ([]*Credentials, error) {
+	resolved := make([]*Credentials, 0, len(providers))
+	for _, provider := range providers {
+		credentials, err := provider.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = append(resolved, credentials)
+	}
+
+	return resolved, nil
+}
+
+// addEnvVars adds the env vars of the host that are set (and not empty) to the credentials.
+func addEnvVars(target map[string]string, keys ...string) {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			target[key] = value
+		}
+	}
+}
+
+func getSortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,98 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSStaticKeys_Resolve(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "very-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	credentials, err := (&AWSStaticKeys{}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"AWS_REGION": "eu-west-1"}, credentials.EnvVars)
+	assert.Len(t, credentials.SecretEnvVars, 2)
+
+	summary := credentials.GetSummary()
+	assert.Equal(t, "aws-static-keys: AWS_REGION=eu-west-1, AWS_ACCESS_KEY_ID=****, AWS_SECRET_ACCESS_KEY=****", summary)
+	assert.NotContains(t, summary, "very-secret")
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = Resolve([]Provider{&AWSStaticKeys{}})
+
+	var notFound *erroer.ErrCredentialsNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "aws-static-keys", notFound.Provider)
+}
+
+func TestAWSWebIdentity_Resolve(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("jwt"), 0o600))
+
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/ci")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+
+	credentials, err := (&AWSWebIdentity{}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/run/secrets/terradagger/aws-web-identity-token", credentials.EnvVars["AWS_WEB_IDENTITY_TOKEN_FILE"])
+	assert.Len(t, credentials.Mounts, 1)
+	assert.Equal(t, tokenFile, credentials.Mounts[0].HostPath)
+	assert.True(t, credentials.Mounts[0].Secret)
+
+	_, err = (&AWSWebIdentity{TokenFile: filepath.Join(t.TempDir(), "missing")}).Resolve()
+	assert.Error(t, err)
+}
+
+func TestAWSProfile_Resolve(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("AWS_PROFILE", "sso-dev")
+
+	credentials, err := (&AWSProfile{ConfigDir: configDir}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "sso-dev", credentials.EnvVars["AWS_PROFILE"])
+	assert.Equal(t, "/root/.aws", credentials.Mounts[0].ContainerPath)
+}
+
+func TestGCPServiceAccount_Resolve(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("GOOGLE_CREDENTIALS", `{"type": "service_account"}`)
+	t.Setenv("GOOGLE_PROJECT", "my-project")
+
+	credentials, err := (&GCPServiceAccount{}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "my-project", credentials.EnvVars["GOOGLE_PROJECT"])
+	assert.Contains(t, credentials.SecretEnvVars, "GOOGLE_CREDENTIALS")
+	assert.Empty(t, credentials.Mounts)
+
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("{}"), 0o600))
+
+	credentials, err = (&GCPServiceAccount{KeyFile: keyFile}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/run/secrets/terradagger/gcp-credentials.json", credentials.EnvVars["GOOGLE_APPLICATION_CREDENTIALS"])
+}
+
+func TestAzureServicePrincipal_Resolve(t *testing.T) {
+	t.Setenv("ARM_CLIENT_ID", "client")
+	t.Setenv("ARM_TENANT_ID", "tenant")
+	t.Setenv("ARM_SUBSCRIPTION_ID", "subscription")
+	t.Setenv("ARM_CLIENT_CERTIFICATE_PATH", "")
+	t.Setenv("ARM_CLIENT_SECRET", "")
+
+	_, err := (&AzureServicePrincipal{}).Resolve()
+	assert.Error(t, err)
+
+	t.Setenv("ARM_CLIENT_SECRET", "secret")
+
+	credentials, err := (&AzureServicePrincipal{}).Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "client", credentials.EnvVars["ARM_CLIENT_ID"])
+	assert.Equal(t, map[string]string{"ARM_CLIENT_SECRET": "secret"}, credentials.SecretEnvVars)
+}
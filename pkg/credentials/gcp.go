@@ -0,0 +1,78 @@
+package credentials
+
+import (
+	"os"
+	"path"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/env"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const gcpApplicationCredentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+
+// gcpSecretEnvVars are the GOOGLE_* env vars that hold credentials.
+var gcpSecretEnvVars = map[string]bool{
+	"GOOGLE_CREDENTIALS":            true,
+	"GOOGLE_CLOUD_KEYFILE_JSON":     true,
+	"GOOGLE_OAUTH_ACCESS_TOKEN":     true,
+	gcpApplicationCredentialsEnvVar: true,
+}
+
+// GCPServiceAccount injects a service account key file (mounted as a secret, and pointed by
+// GOOGLE_APPLICATION_CREDENTIALS), or the inline GOOGLE_CREDENTIALS of the host, and the other
+// GOOGLE_* env vars (e.g. GOOGLE_PROJECT).
+type GCPServiceAccount struct {
+	// KeyFile is the service account key file. Defaults to the GOOGLE_APPLICATION_CREDENTIALS of the host.
+	KeyFile string
+}
+
+func (p *GCPServiceAccount) GetName() string {
+	return "gcp-service-account"
+}
+
+func (p *GCPServiceAccount) Resolve() (*Credentials, error) {
+	credentials := newCredentials(p.GetName())
+
+	googleEnvVars, _ := env.GetAllEnvVarsWithPrefix("GOOGLE_")
+	for key, value := range googleEnvVars {
+		if value == "" {
+			continue
+		}
+
+		if !gcpSecretEnvVars[key] {
+			credentials.EnvVars[key] = value
+		} else if key != gcpApplicationCredentialsEnvVar {
+			credentials.SecretEnvVars[key] = value
+		}
+	}
+
+	keyFile := p.KeyFile
+	if keyFile == "" {
+		keyFile = os.Getenv(gcpApplicationCredentialsEnvVar)
+	}
+
+	if keyFile == "" {
+		if len(credentials.SecretEnvVars) == 0 {
+			return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(),
+				"the key file is empty, and neither GOOGLE_APPLICATION_CREDENTIALS nor GOOGLE_CREDENTIALS are set", nil)
+		}
+
+		return credentials, nil
+	}
+
+	mount := container.Mount{
+		HostPath:      keyFile,
+		ContainerPath: path.Join(secretsDir, "gcp-credentials.json"),
+		Secret:        true,
+	}
+
+	if err := mount.Validate(); err != nil {
+		return nil, erroer.NewErrCredentialsNotFoundError(p.GetName(), "the service account key file doesn't exist", err)
+	}
+
+	credentials.EnvVars[gcpApplicationCredentialsEnvVar] = mount.ContainerPath
+	credentials.Mounts = append(credentials.Mounts, mount)
+
+	return credentials, nil
+}
@@ -2,21 +2,63 @@ package daggerx
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
 
 	"github.com/Excoriate/go-terradagger/pkg/logger"
 
 	"dagger.io/dagger"
 )
 
+// runnerHostEnvVar is the environment variable read by the Dagger CLI to connect to a specific engine
+// (e.g. docker-image://registry.dagger.io/engine:v0.9.8, or tcp://dagger-engine:1234).
+const runnerHostEnvVar = "_EXPERIMENTAL_DAGGER_RUNNER_HOST"
+
+// runnerHostMu serializes the connections of the engines, since the runner host is passed to the
+// Dagger CLI through the environment of the process.
+var runnerHostMu sync.Mutex
+
+type Verbosity int
+
+const (
+	// VerbosityDefault writes the engine logs into the log output (os.Stdout if it isn't set).
+	VerbosityDefault Verbosity = iota
+	// VerbosityQuiet discards the engine logs.
+	VerbosityQuiet
+)
+
+// Options configures how the connection with the Dagger engine is established.
+type Options struct {
+	// LogOutput is where the engine logs are written. Defaults to os.Stdout.
+	LogOutput io.Writer
+	// Workdir is the host directory the engine uses as its working directory.
+	Workdir string
+	// Verbosity controls whether the engine logs are written or discarded.
+	Verbosity Verbosity
+	// ConnectTimeout is the maximum time to wait for the engine to start. Zero means no timeout.
+	ConnectTimeout time.Duration
+	// RunnerHost overrides the engine the client connects to (_EXPERIMENTAL_DAGGER_RUNNER_HOST).
+	RunnerHost string
+}
+
 type Engine interface {
 	Start(ctx context.Context, options ...dagger.ClientOpt) (*dagger.Client, error)
 	GetEngine() *dagger.Client
+	Ping(ctx context.Context) error
+	Close() error
 }
 
 type DaggerEngine struct {
-	l logger.Log
-	c *dagger.Client
+	l       logger.Log
+	c       *dagger.Client
+	options Options
+	// cancelConnect cancels the context of a connection started with a timeout, when the engine is closed.
+	cancelConnect context.CancelFunc
 }
 
 func New(l logger.Log) Engine {
@@ -25,6 +67,34 @@ func New(l logger.Log) Engine {
 	}
 }
 
+// NewWithOptions creates an engine that connects using the given options. The dagger.ClientOpt passed
+// to Start are applied after them, so they override the log output and the workdir of the options.
+func NewWithOptions(l logger.Log, options Options) Engine {
+	return &DaggerEngine{
+		l:       l,
+		options: options,
+	}
+}
+
+func (b *DaggerEngine) getClientOptions() []dagger.ClientOpt {
+	var logOutput io.Writer = os.Stdout
+	if b.options.LogOutput != nil {
+		logOutput = b.options.LogOutput
+	}
+
+	if b.options.Verbosity == VerbosityQuiet {
+		logOutput = io.Discard
+	}
+
+	daggerOptions := []dagger.ClientOpt{dagger.WithLogOutput(logOutput)}
+
+	if b.options.Workdir != "" {
+		daggerOptions = append(daggerOptions, dagger.WithWorkdir(b.options.Workdir))
+	}
+
+	return daggerOptions
+}
+
 func (b *DaggerEngine) Start(ctx context.Context, options ...dagger.ClientOpt) (*dagger.
 	Client, error) {
 	var c context.Context
@@ -34,17 +104,9 @@ func (b *DaggerEngine) Start(ctx context.Context, options ...dagger.ClientOpt) (
 		c = ctx
 	}
 
-	var daggerOptions []dagger.ClientOpt
-
-	var daggerClient *dagger.Client
-
-	if len(options) == 0 {
-		daggerOptions = append(daggerOptions, dagger.WithLogOutput(os.Stdout))
-	} else {
-		daggerOptions = append(daggerOptions, options...)
-	}
+	daggerOptions := append(b.getClientOptions(), options...)
 
-	daggerClient, err := dagger.Connect(c, daggerOptions...)
+	daggerClient, err := b.connect(c, daggerOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +115,136 @@ func (b *DaggerEngine) Start(ctx context.Context, options ...dagger.ClientOpt) (
 	return daggerClient, nil
 }
 
+// daggerConnect connects with the engine. It's a variable, so the tests can replace it.
+var daggerConnect = dagger.Connect
+
+// withRunnerHost runs connect with the runner host set in the environment, and restores the environment
+// afterwards. Dagger has no client option for the runner host: the CLI it starts inherits it from the
+// environment when the connection starts. The connections are serialized, so every engine sees its own
+// runner host (or the one of the process, if it has none).
+func withRunnerHost(runnerHost string, connect func() error) error {
+	runnerHostMu.Lock()
+	defer runnerHostMu.Unlock()
+
+	if runnerHost == "" {
+		return connect()
+	}
+
+	previous, hasPrevious := os.LookupEnv(runnerHostEnvVar)
+	if err := os.Setenv(runnerHostEnvVar, runnerHost); err != nil {
+		return erroer.NewErrDaggerEngineUnavailableError(fmt.Sprintf("unable to set the runner host %s", runnerHost), err)
+	}
+
+	defer func() {
+		if hasPrevious {
+			_ = os.Setenv(runnerHostEnvVar, previous)
+		} else {
+			_ = os.Unsetenv(runnerHostEnvVar)
+		}
+	}()
+
+	return connect()
+}
+
+// connectWithRunnerHost connects with the engine, with the runner host of the options. The runner host
+// stays set until the connection returns, even if Start gave up waiting for it.
+func (b *DaggerEngine) connectWithRunnerHost(ctx context.Context, daggerOptions []dagger.ClientOpt) (*dagger.Client, error) {
+	var daggerClient *dagger.Client
+	err := withRunnerHost(b.options.RunnerHost, func() error {
+		var connectErr error
+		daggerClient, connectErr = daggerConnect(ctx, daggerOptions...)
+		return connectErr
+	})
+
+	return daggerClient, err
+}
+
+// connect connects with the engine, waiting at most ConnectTimeout. The context can't be used for the
+// timeout, since cancelling it also terminates the engine session once it's connected. Instead, the
+// connection gets its own context, cancelled when the timeout fires (so the late connection returns,
+// and releases the runner host), or when the engine is closed.
+func (b *DaggerEngine) connect(ctx context.Context, daggerOptions []dagger.ClientOpt) (*dagger.Client, error) {
+	if b.options.ConnectTimeout <= 0 {
+		daggerClient, err := b.connectWithRunnerHost(ctx, daggerOptions)
+		if err != nil {
+			return nil, erroer.NewErrDaggerEngineUnavailableError("unable to connect with the Dagger engine, is a container runtime (e.g. docker) running?", err)
+		}
+
+		return daggerClient, nil
+	}
+
+	type connectResult struct {
+		c   *dagger.Client
+		err error
+	}
+
+	connectCtx, cancel := context.WithCancel(ctx)
+
+	resultCh := make(chan connectResult, 1)
+	go func() {
+		daggerClient, err := b.connectWithRunnerHost(connectCtx, daggerOptions)
+		resultCh <- connectResult{c: daggerClient, err: err}
+	}()
+
+	timer := time.NewTimer(b.options.ConnectTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-resultCh:
+		if result.err != nil {
+			cancel()
+			return nil, erroer.NewErrDaggerEngineUnavailableError("unable to connect with the Dagger engine, is a container runtime (e.g. docker) running?", result.err)
+		}
+
+		b.cancelConnect = cancel
+		return result.c, nil
+	case <-timer.C:
+		// Abort the connection, and close it if it still succeeded, so the engine session isn't leaked.
+		cancel()
+		go func() {
+			if result := <-resultCh; result.c != nil {
+				_ = result.c.Close()
+			}
+		}()
+
+		return nil, erroer.NewErrDaggerEngineUnavailableError(fmt.Sprintf("the Dagger engine didn't start after %s, is a container runtime (e.g. docker) running?", b.options.ConnectTimeout), nil)
+	}
+}
+
 func (b *DaggerEngine) GetEngine() *dagger.Client {
 	return b.c
 }
+
+// Ping checks that the engine is started and responds to queries.
+func (b *DaggerEngine) Ping(ctx context.Context) error {
+	if b.c == nil {
+		return erroer.NewErrDaggerEngineUnavailableError("the Dagger engine isn't started", nil)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, err := b.c.DefaultPlatform(ctx); err != nil {
+		return erroer.NewErrDaggerEngineUnavailableError("the Dagger engine doesn't respond", err)
+	}
+
+	return nil
+}
+
+// Close closes the connection with the Dagger engine, if it was started.
+func (b *DaggerEngine) Close() error {
+	if b.c == nil {
+		return nil
+	}
+
+	err := b.c.Close()
+	b.c = nil
+
+	if b.cancelConnect != nil {
+		b.cancelConnect()
+		b.cancelConnect = nil
+	}
+
+	return err
+}
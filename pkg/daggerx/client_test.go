@@ -0,0 +1,96 @@
+package daggerx
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRunnerHost(t *testing.T) {
+	t.Setenv(runnerHostEnvVar, "docker-image://registry.dagger.io/engine:v0.9.8")
+
+	var seen string
+	err := withRunnerHost("tcp://dagger-engine:1234", func() error {
+		seen = os.Getenv(runnerHostEnvVar)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp://dagger-engine:1234", seen)
+	assert.Equal(t, "docker-image://registry.dagger.io/engine:v0.9.8", os.Getenv(runnerHostEnvVar))
+
+	// Without a runner host, the one of the process is kept.
+	assert.NoError(t, withRunnerHost("", func() error {
+		seen = os.Getenv(runnerHostEnvVar)
+		return nil
+	}))
+	assert.Equal(t, "docker-image://registry.dagger.io/engine:v0.9.8", seen)
+}
+
+func TestWithRunnerHost_Unset(t *testing.T) {
+	t.Setenv(runnerHostEnvVar, "")
+	assert.NoError(t, os.Unsetenv(runnerHostEnvVar))
+
+	assert.NoError(t, withRunnerHost("tcp://dagger-engine:1234", func() error { return nil }))
+
+	_, ok := os.LookupEnv(runnerHostEnvVar)
+	assert.False(t, ok)
+}
+
+func TestWithRunnerHost_Concurrent(t *testing.T) {
+	t.Setenv(runnerHostEnvVar, "")
+
+	var wg sync.WaitGroup
+	for _, host := range []string{"tcp://a:1234", "tcp://b:1234", "tcp://c:1234", "tcp://d:1234"} {
+		host := host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 50; i++ {
+				assert.NoError(t, withRunnerHost(host, func() error {
+					assert.Equal(t, host, os.Getenv(runnerHostEnvVar))
+					return nil
+				}))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestDaggerEngine_Start_Timeout(t *testing.T) {
+	t.Setenv(runnerHostEnvVar, "")
+	assert.NoError(t, os.Unsetenv(runnerHostEnvVar))
+
+	// The connection hangs until it's cancelled, and reports the runner host it sees when it returns.
+	seen := make(chan string, 1)
+	daggerConnect = func(ctx context.Context, _ ...dagger.ClientOpt) (*dagger.Client, error) {
+		<-ctx.Done()
+		seen <- os.Getenv(runnerHostEnvVar)
+		return nil, ctx.Err()
+	}
+	defer func() { daggerConnect = dagger.Connect }()
+
+	engine := NewWithOptions(logger.NewLogger().Logger, Options{
+		ConnectTimeout: 10 * time.Millisecond,
+		RunnerHost:     "tcp://dagger-engine:1234",
+		Verbosity:      VerbosityQuiet,
+	})
+
+	_, err := engine.Start(context.Background())
+	assert.Error(t, err)
+
+	// The late connection still sees its runner host, and the environment is restored once it returns.
+	assert.Equal(t, "tcp://dagger-engine:1234", <-seen)
+
+	runnerHostMu.Lock()
+	_, ok := os.LookupEnv(runnerHostEnvVar)
+	runnerHostMu.Unlock()
+	assert.False(t, ok)
+}
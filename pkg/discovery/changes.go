@@ -0,0 +1,210 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+)
+
+type ChangesOptions struct {
+	// BaseRef is the git ref the changes are compared with, e.g. origin/main.
+	BaseRef string
+	// VarFiles are the var files (relative to the workspace) used by each module (keyed by module
+	// path) that live outside the module directory, e.g. envs/dev.tfvars.
+	VarFiles map[string][]string
+	// IgnoreUncommitted only compares the commits, ignoring the uncommitted and untracked files.
+	IgnoreUncommitted bool
+}
+
+// ChangeSet are the modules affected by the changes since the base ref.
+type ChangeSet struct {
+	BaseRef string `json:"base_ref"`
+	// ChangedFiles are the changed files, relative to the workspace.
+	ChangedFiles []string `json:"changed_files"`
+	// Affected are the modules that changed, or that call (directly or not) a local module that changed.
+	Affected []Module `json:"affected"`
+}
+
+// GetModulePaths returns the paths of the affected modules.
+func (c *ChangeSet) GetModulePaths() []string {
+	paths := make([]string, 0, len(c.Affected))
+	for _, module := range c.Affected {
+		paths = append(paths, module.Path)
+	}
+
+	return paths
+}
+
+// GetTfOptions returns the options of the affected terraform root modules, based on the given ones
+// (only the module path changes), ready to be passed to terraform.PlanManyE.
+func (c *ChangeSet) GetTfOptions(base terraformcore.TfOptions) []*terraformcore.TfOptions {
+	var options []*terraformcore.TfOptions
+	for _, module := range c.Affected {
+		if module.Kind != ModuleKindRoot {
+			continue
+		}
+
+		moduleOptions := base
+		moduleOptions.ModulePath = module.Path
+		options = append(options, &moduleOptions)
+	}
+
+	return options
+}
+
+// DetectChanges discovers the modules of the terradagger workspace, and returns the ones affected by
+// the changes since the base ref. The workspace must be (in) a local git repository.
+func DetectChanges(td *terradagger.TD, options ChangesOptions) (*ChangeSet, error) {
+	modules, err := Discover(td)
+	if err != nil {
+		return nil, err
+	}
+
+	changedFiles, err := GetChangedFiles(td.Config.GetWorkspaceAbs(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangeSet{
+		BaseRef:      options.BaseRef,
+		ChangedFiles: changedFiles,
+		Affected:     GetAffectedModules(modules, changedFiles, options.VarFiles),
+	}, nil
+}
+
+// GetChangedFiles returns the files (relative to the workspace) that changed since the merge base of
+// the base ref and HEAD, including the uncommitted and untracked files (unless they're ignored).
+func GetChangedFiles(workspaceAbs string, options ChangesOptions) ([]string, error) {
+	if options.BaseRef == "" {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError("the base ref cannot be empty", nil)
+	}
+
+	mergeBase, err := runGit(workspaceAbs, "merge-base", options.BaseRef, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	diffArgs := []string{"diff", "--name-only", "--relative", strings.TrimSpace(mergeBase)}
+	if options.IgnoreUncommitted {
+		diffArgs = append(diffArgs, "HEAD")
+	}
+
+	diff, err := runGit(workspaceAbs, diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	for _, file := range strings.Split(diff, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			files[filepath.ToSlash(file)] = true
+		}
+	}
+
+	if !options.IgnoreUncommitted {
+		untracked, err := runGit(workspaceAbs, "ls-files", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range strings.Split(untracked, "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				files[filepath.ToSlash(file)] = true
+			}
+		}
+	}
+
+	return getSortedKeys(files), nil
+}
+
+// GetAffectedModules returns the modules affected by the changed files (relative to the workspace):
+// the modules that contain a changed file (or a changed var file), and the modules that call them
+// through a local source, transitively.
+func GetAffectedModules(modules []Module, changedFiles []string, varFiles map[string][]string) []Module {
+	affected := map[string]bool{}
+
+	for _, file := range changedFiles {
+		if module := getOwnerModule(modules, file); module != "" {
+			affected[module] = true
+		}
+
+		for module, files := range varFiles {
+			for _, varFile := range files {
+				if filepath.ToSlash(filepath.Clean(varFile)) == file {
+					affected[module] = true
+				}
+			}
+		}
+	}
+
+	// Propagate the changes to the callers, until nothing else changes.
+	for changed := true; changed; {
+		changed = false
+		for _, module := range modules {
+			if affected[module.Path] {
+				continue
+			}
+
+			for _, source := range module.LocalSources {
+				if affected[source] {
+					affected[module.Path] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var result []Module
+	for _, module := range modules {
+		if affected[module.Path] {
+			result = append(result, module)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+// getOwnerModule returns the path of the deepest module that contains the file, if any.
+func getOwnerModule(modules []Module, file string) string {
+	owner, ownerDepth := "", -1
+	for _, module := range modules {
+		depth := len(module.Path)
+		if module.Path == "." {
+			depth = 0
+		} else if !strings.HasPrefix(file, module.Path+"/") {
+			continue
+		}
+
+		if depth > ownerDepth {
+			owner, ownerDepth = module.Path, depth
+		}
+	}
+
+	return owner
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", erroer.NewErrDiscoveryGitError(fmt.Sprintf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String())), err)
+	}
+
+	return stdout.String(), nil
+}
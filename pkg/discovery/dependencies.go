@@ -0,0 +1,129 @@
+package discovery
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	backendLocal      = "local"
+	defaultLocalState = "terraform.tfstate"
+)
+
+// stateIdentityAttributes are the backend attributes that identify a state (e.g. the s3 key). A remote
+// state matches a module when they share at least one of them, and all the shared attributes are equal.
+var stateIdentityAttributes = []string{"key", "prefix", "path", "name", "workspace_key_prefix"}
+
+// InferDependencies returns, for each terraform root module, the root modules it depends on, inferred
+// from its terraform_remote_state data sources (matched against the backend of the other modules).
+// Remote states that don't match any module (e.g. a state managed somewhere else) are ignored.
+func InferDependencies(modules []Module) map[string][]string {
+	dependencies := map[string][]string{}
+
+	for _, module := range modules {
+		if module.Kind != ModuleKindRoot {
+			continue
+		}
+
+		deps := map[string]bool{}
+		for _, remoteState := range module.RemoteStates {
+			for _, candidate := range modules {
+				if candidate.Kind != ModuleKindRoot || candidate.Path == module.Path {
+					continue
+				}
+
+				if isRemoteStateOf(module, remoteState, candidate) {
+					deps[candidate.Path] = true
+				}
+			}
+		}
+
+		dependencies[module.Path] = getSortedKeys(deps)
+	}
+
+	return dependencies
+}
+
+// MergeDependencies merges the declared dependencies (e.g. the ones declared by the user) into the
+// inferred ones.
+func MergeDependencies(inferred, declared map[string][]string) map[string][]string {
+	merged := map[string][]string{}
+	for _, dependencies := range []map[string][]string{inferred, declared} {
+		for module, deps := range dependencies {
+			unique := map[string]bool{}
+			for _, dep := range merged[module] {
+				unique[dep] = true
+			}
+
+			for _, dep := range deps {
+				unique[dep] = true
+			}
+
+			merged[module] = getSortedKeys(unique)
+		}
+	}
+
+	return merged
+}
+
+func isRemoteStateOf(consumer Module, remoteState RemoteState, candidate Module) bool {
+	backend := remoteState.Backend
+	if backend == "" {
+		backend = backendLocal
+	}
+
+	candidateBackend := candidate.Backend
+	if candidateBackend == "" {
+		candidateBackend = backendLocal
+	}
+
+	if backend != candidateBackend {
+		return false
+	}
+
+	if backend == backendLocal {
+		return isLocalStateOf(consumer, remoteState, candidate)
+	}
+
+	shared := 0
+	for attribute, value := range remoteState.Config {
+		candidateValue, ok := candidate.BackendConfig[attribute]
+		if !ok || !isLiteral(value) || !isLiteral(candidateValue) {
+			continue
+		}
+
+		if value != candidateValue {
+			return false
+		}
+
+		for _, identity := range stateIdentityAttributes {
+			if attribute == identity {
+				shared++
+			}
+		}
+	}
+
+	return shared > 0
+}
+
+// isLocalStateOf checks whether the path of a local remote state (relative to the consumer) is the
+// state file of the candidate module.
+func isLocalStateOf(consumer Module, remoteState RemoteState, candidate Module) bool {
+	statePath, ok := remoteState.Config["path"]
+	if !ok || !isLiteral(statePath) {
+		return false
+	}
+
+	candidateState := defaultLocalState
+	if path, ok := candidate.BackendConfig["path"]; ok && isLiteral(path) {
+		candidateState = path
+	}
+
+	return filepath.Join(consumer.PathAbs, statePath) == filepath.Join(candidate.PathAbs, candidateState)
+}
+
+// isLiteral checks whether the value is a string literal, instead of an expression (e.g. var.key).
+func isLiteral(value string) bool {
+	return value != "" && !strings.Contains(value, "${") && !strings.ContainsAny(value, "()[]{}") &&
+		!strings.HasPrefix(value, "var.") && !strings.HasPrefix(value, "local.")
+}
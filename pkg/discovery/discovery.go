@@ -0,0 +1,338 @@
+package discovery
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/config"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
+)
+
+const (
+	// ModuleKindRoot is a terraform module that can be applied on its own (it has a backend, or providers).
+	ModuleKindRoot = "root"
+	// ModuleKindChild is a terraform module that's meant to be called by other modules.
+	ModuleKindChild = "child"
+	// ModuleKindTerragruntUnit is a directory with a terragrunt.hcl file.
+	ModuleKindTerragruntUnit = "terragrunt-unit"
+
+	terragruntConfigFile = "terragrunt.hcl"
+	terraformExtension   = ".tf"
+)
+
+// alwaysExcludedDirs are never walked: they're created by terraform and terragrunt, and contain copies
+// of the modules, or they're the git history.
+var alwaysExcludedDirs = []string{".git", ".terraform", ".terragrunt-cache"}
+
+type Module struct {
+	// Path is the module directory, relative to the workspace.
+	Path string `json:"path"`
+	// PathAbs is the absolute path of the module directory.
+	PathAbs string `json:"path_abs"`
+	// Tool is the IaC tool that runs the module (terraform or terragrunt).
+	Tool string `json:"tool"`
+	// Kind is the kind of module: root, child or terragrunt-unit.
+	Kind string `json:"kind"`
+	// VersionConstraint is the terraform required_version (or the terragrunt terraform_version_constraint).
+	VersionConstraint string `json:"version_constraint,omitempty"`
+	// Backend is the type of the backend, e.g. s3.
+	Backend string `json:"backend,omitempty"`
+	// BackendConfig are the attributes of the backend block, e.g. bucket and key.
+	BackendConfig map[string]string `json:"backend_config,omitempty"`
+	// Providers are the providers configured in the module.
+	Providers []string `json:"providers,omitempty"`
+	// LocalSources are the local modules called by the module (e.g. source = "../modules/x"),
+	// relative to the workspace.
+	LocalSources []string `json:"local_sources,omitempty"`
+	// RemoteStates are the terraform_remote_state data sources of the module.
+	RemoteStates []RemoteState `json:"remote_states,omitempty"`
+}
+
+// RemoteState is a terraform_remote_state data source, which reads the outputs of another root module.
+type RemoteState struct {
+	Name    string            `json:"name"`
+	Backend string            `json:"backend"`
+	Config  map[string]string `json:"config,omitempty"`
+}
+
+type Options struct {
+	// WorkspaceAbs is the absolute path of the directory to walk.
+	WorkspaceAbs string
+	// ExcludedDirs are the directories (or glob patterns, relative to the workspace) that aren't walked.
+	ExcludedDirs []string
+}
+
+// Discover walks the terradagger workspace, and returns the terraform and terragrunt modules it contains.
+func Discover(td *terradagger.TD) ([]Module, error) {
+	return DiscoverWithOptions(Options{
+		WorkspaceAbs: td.Config.GetWorkspaceAbs(),
+		ExcludedDirs: td.Config.GetExcludedDirs(),
+	})
+}
+
+// DiscoverWithOptions walks the workspace, and returns the modules it contains, sorted by path.
+func DiscoverWithOptions(options Options) ([]Module, error) {
+	if err := utils.IsValidDirE(options.WorkspaceAbs); err != nil {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError("the workspace is not a valid directory", err)
+	}
+
+	var modules []Module
+
+	err := filepath.WalkDir(options.WorkspaceAbs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(options.WorkspaceAbs, path)
+		if relErr != nil {
+			return relErr
+		}
+
+		if rel != "." && isExcludedDir(rel, options.ExcludedDirs) {
+			return filepath.SkipDir
+		}
+
+		module, found, moduleErr := getModule(options.WorkspaceAbs, rel)
+		if moduleErr != nil {
+			return moduleErr
+		}
+
+		if found {
+			modules = append(modules, *module)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError(fmt.Sprintf("unable to walk the workspace %s", options.WorkspaceAbs), err)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+
+	return modules, nil
+}
+
+// isExcludedDir checks whether the directory (relative to the workspace) matches any of the patterns.
+// Patterns ending with /** exclude the whole tree, and patterns without a slash match the directory name
+// at any level.
+func isExcludedDir(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(rel)
+
+	for _, excluded := range alwaysExcludedDirs {
+		if name == excluded {
+			return true
+		}
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/**")
+		pattern = strings.TrimSuffix(pattern, "/")
+
+		if pattern == "" {
+			continue
+		}
+
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getModule inspects the directory, and returns the module it contains, if any.
+func getModule(workspaceAbs, rel string) (*Module, bool, error) {
+	dirAbs := filepath.Join(workspaceAbs, rel)
+
+	if err := utils.IsValidFileE(filepath.Join(dirAbs, terragruntConfigFile)); err == nil {
+		module, err := getTerragruntUnit(workspaceAbs, rel)
+		return module, err == nil, err
+	}
+
+	files, err := getTerraformFiles(dirAbs)
+	if err != nil || len(files) == 0 {
+		return nil, false, err
+	}
+
+	module, err := getTerraformModule(workspaceAbs, rel, files)
+	return module, err == nil, err
+}
+
+// getTerraformFiles returns the .tf and .tf.json files of the directory, sorted.
+func getTerraformFiles(dirAbs string) ([]string, error) {
+	var files []string
+	for _, extension := range []string{terraformExtension, terraformJSONExtension} {
+		matches, err := filepath.Glob(filepath.Join(dirAbs, "*"+extension))
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, matches...)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func getTerragruntUnit(workspaceAbs, rel string) (*Module, error) {
+	dirAbs := filepath.Join(workspaceAbs, rel)
+
+	file, err := parseHCLFile(filepath.Join(dirAbs, terragruntConfigFile))
+	if err != nil {
+		return nil, err
+	}
+
+	module := &Module{
+		Path:              filepath.ToSlash(rel),
+		PathAbs:           dirAbs,
+		Tool:              config.IacToolTerragrunt,
+		Kind:              ModuleKindTerragruntUnit,
+		VersionConstraint: file.Attributes["terraform_version_constraint"],
+	}
+
+	for _, remoteState := range file.getBlocks("remote_state") {
+		module.Backend = remoteState.Attributes["backend"]
+	}
+
+	for _, terraformBlock := range file.getBlocks("terraform") {
+		if source := getLocalSource(workspaceAbs, rel, terraformBlock.Attributes["source"]); source != "" {
+			module.LocalSources = append(module.LocalSources, source)
+		}
+	}
+
+	return module, nil
+}
+
+func getTerraformModule(workspaceAbs, rel string, files []string) (*Module, error) {
+	dirAbs := filepath.Join(workspaceAbs, rel)
+
+	module := &Module{
+		Path:    filepath.ToSlash(rel),
+		PathAbs: dirAbs,
+		Tool:    config.IacToolTerraform,
+		Kind:    ModuleKindChild,
+	}
+
+	providers := map[string]bool{}
+	sources := map[string]bool{}
+
+	for _, f := range files {
+		file, err := parseHCLFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, terraformBlock := range file.getBlocks("terraform") {
+			if constraint, ok := terraformBlock.Attributes["required_version"]; ok {
+				module.VersionConstraint = constraint
+			}
+
+			for _, backend := range terraformBlock.getBlocks("backend") {
+				if len(backend.Labels) > 0 {
+					module.Backend = backend.Labels[0]
+					module.BackendConfig = backend.Attributes
+				}
+			}
+
+			if len(terraformBlock.getBlocks("cloud")) > 0 {
+				module.Backend = "cloud"
+			}
+		}
+
+		for _, provider := range file.getBlocks("provider") {
+			if len(provider.Labels) > 0 {
+				providers[provider.Labels[0]] = true
+			}
+		}
+
+		for _, data := range file.getBlocks("data") {
+			if len(data.Labels) < 2 || data.Labels[0] != "terraform_remote_state" {
+				continue
+			}
+
+			module.RemoteStates = append(module.RemoteStates, RemoteState{
+				Name:    data.Labels[1],
+				Backend: data.Attributes["backend"],
+				Config:  data.getObject("config"),
+			})
+		}
+
+		for _, moduleBlock := range file.getBlocks("module") {
+			if source := getLocalSource(workspaceAbs, rel, moduleBlock.Attributes["source"]); source != "" {
+				sources[source] = true
+			}
+		}
+	}
+
+	if module.Backend != "" || len(providers) > 0 {
+		module.Kind = ModuleKindRoot
+	}
+
+	module.Providers = getSortedKeys(providers)
+	module.LocalSources = getSortedKeys(sources)
+
+	return module, nil
+}
+
+// getLocalSource returns the path (relative to the workspace) of a local module source, e.g. ../modules/x.
+// Remote sources (registry, git, etc.) return an empty string.
+func getLocalSource(workspaceAbs, rel, source string) string {
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return ""
+	}
+
+	sourceAbs := filepath.Join(workspaceAbs, rel, source)
+	sourceRel, err := filepath.Rel(workspaceAbs, sourceAbs)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.ToSlash(sourceRel)
+}
+
+func getSortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// GetRootModules returns the modules that can be planned or applied on their own: terraform root
+// modules, and terragrunt units.
+func GetRootModules(modules []Module) []Module {
+	var roots []Module
+	for _, module := range modules {
+		if module.Kind != ModuleKindChild {
+			roots = append(roots, module)
+		}
+	}
+
+	return roots
+}
@@ -0,0 +1,312 @@
+package discovery
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/config"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestParseHCL(t *testing.T) {
+	file, err := parseHCL(`
+# A comment with a { brace
+terraform {
+  required_version = ">= 1.5.0" // another comment
+  backend "s3" {
+    bucket = "my-bucket"
+    key    = "network/terraform.tfstate"
+    assume_role {
+      role_arn = "arn:aws:iam::123456789012:role/state"
+    }
+  }
+}
+
+locals {
+  policy = <<EOF
+{ "Statement": [] }
+module "not_a_module" {
+EOF
+  name = "${var.prefix}-network"
+}
+
+/* a block comment
+module "ignored" {}
+*/
+module "vpc" {
+  source = "../modules/vpc"
+  name   = "${local.name}-{vpc}"
+}
+
+data "terraform_remote_state" "network" {
+  backend = "s3"
+  config  = { bucket = "my-bucket", "key" = "network/terraform.tfstate", tags = { Name = "x" } }
+}
+`)
+	assert.NoError(t, err)
+
+	terraformBlocks := file.getBlocks("terraform")
+	assert.Len(t, terraformBlocks, 1)
+	assert.Equal(t, ">= 1.5.0", terraformBlocks[0].Attributes["required_version"])
+
+	backends := terraformBlocks[0].getBlocks("backend")
+	assert.Len(t, backends, 1)
+	assert.Equal(t, []string{"s3"}, backends[0].Labels)
+	assert.Equal(t, map[string]string{"bucket": "my-bucket", "key": "network/terraform.tfstate"}, backends[0].Attributes)
+
+	modules := file.getBlocks("module")
+	assert.Len(t, modules, 1)
+	assert.Equal(t, []string{"vpc"}, modules[0].Labels)
+	assert.Equal(t, "../modules/vpc", modules[0].Attributes["source"])
+
+	data := file.getBlocks("data")
+	assert.Len(t, data, 1)
+	assert.Equal(t, "s3", data[0].Attributes["backend"])
+	assert.Equal(t, map[string]string{
+		"bucket": "my-bucket",
+		"key":    "network/terraform.tfstate",
+		"tags":   `{ Name = "x" }`,
+	}, data[0].getObject("config"))
+
+	_, err = parseHCL(`terraform {`)
+	assert.Error(t, err)
+}
+
+func TestParseHCLFile_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf.json")
+	writeTestFile(t, path, `{
+  "terraform": {
+    "required_version": ">= 1.5.0",
+    "backend": { "s3": { "bucket": "my-bucket", "key": "network/terraform.tfstate" } }
+  },
+  "provider": { "aws": { "region": "us-east-1" } },
+  "module": { "vpc": { "source": "../modules/vpc" } }
+}`)
+
+	file, err := parseHCLFile(path)
+	assert.NoError(t, err)
+
+	terraformBlocks := file.getBlocks("terraform")
+	assert.Len(t, terraformBlocks, 1)
+	assert.Equal(t, ">= 1.5.0", terraformBlocks[0].Attributes["required_version"])
+
+	backends := terraformBlocks[0].getBlocks("backend")
+	assert.Len(t, backends, 1)
+	assert.Equal(t, []string{"s3"}, backends[0].Labels)
+	assert.Equal(t, "network/terraform.tfstate", backends[0].Attributes["key"])
+
+	assert.Equal(t, []string{"aws"}, file.getBlocks("provider")[0].Labels)
+	assert.Equal(t, "../modules/vpc", file.getBlocks("module")[0].Attributes["source"])
+}
+
+func TestDiscoverWithOptions(t *testing.T) {
+	workspace := t.TempDir()
+
+	writeTestFile(t, filepath.Join(workspace, "stacks", "network", "main.tf"), `
+terraform {
+  required_version = "~> 1.6"
+  backend "s3" {}
+}
+
+module "vpc" {
+  source = "../../modules/vpc"
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "stacks", "network", "providers.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "modules", "vpc", "main.tf"), `resource "aws_vpc" "this" {}`)
+	writeTestFile(t, filepath.Join(workspace, "modules", "vpc", ".terraform", "modules", "x", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "live", "dev", "terragrunt.hcl"), `
+terraform_version_constraint = ">= 1.5"
+
+terraform {
+  source = "../../modules//vpc"
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "dist", "stack", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, ".git", "modules", "x", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "live", "dev", ".terragrunt-cache", "abc", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "stacks", "dns", "main.tf.json"), `{"provider": {"aws": {}}}`)
+	writeTestFile(t, filepath.Join(workspace, "web", "package.json"), `{}`)
+	writeTestFile(t, filepath.Join(workspace, "docs", "README.md"), `# docs`)
+
+	modules, err := DiscoverWithOptions(Options{
+		WorkspaceAbs: workspace,
+		ExcludedDirs: []string{"dist/**"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, modules, 4)
+
+	assert.Equal(t, "live/dev", modules[0].Path)
+	assert.Equal(t, config.IacToolTerragrunt, modules[0].Tool)
+	assert.Equal(t, ModuleKindTerragruntUnit, modules[0].Kind)
+	assert.Equal(t, ">= 1.5", modules[0].VersionConstraint)
+	assert.Equal(t, []string{"modules/vpc"}, modules[0].LocalSources)
+
+	assert.Equal(t, "modules/vpc", modules[1].Path)
+	assert.Equal(t, ModuleKindChild, modules[1].Kind)
+
+	assert.Equal(t, "stacks/dns", modules[2].Path)
+	assert.Equal(t, ModuleKindRoot, modules[2].Kind)
+	assert.Equal(t, []string{"aws"}, modules[2].Providers)
+
+	assert.Equal(t, "stacks/network", modules[3].Path)
+	assert.Equal(t, config.IacToolTerraform, modules[3].Tool)
+	assert.Equal(t, ModuleKindRoot, modules[3].Kind)
+	assert.Equal(t, "~> 1.6", modules[3].VersionConstraint)
+	assert.Equal(t, "s3", modules[3].Backend)
+	assert.Equal(t, []string{"aws"}, modules[3].Providers)
+	assert.Equal(t, []string{"modules/vpc"}, modules[3].LocalSources)
+
+	assert.Len(t, GetRootModules(modules), 3)
+}
+
+func TestInferDependencies(t *testing.T) {
+	workspace := t.TempDir()
+
+	writeTestFile(t, filepath.Join(workspace, "network", "main.tf"), `
+terraform {
+  backend "s3" {
+    bucket = "states"
+    key    = "network/terraform.tfstate"
+    region = "us-east-1"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "database", "main.tf"), `
+terraform {
+  backend "s3" {
+    bucket = "states"
+    key    = "database/terraform.tfstate"
+  }
+}
+
+data "terraform_remote_state" "network" {
+  backend = "s3"
+  config = {
+    bucket = "states"
+    key    = "network/terraform.tfstate"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "app", "main.tf"), `
+provider "aws" {}
+
+data "terraform_remote_state" "database" {
+  backend = "s3"
+  config  = { bucket = "states", key = "database/terraform.tfstate" }
+}
+
+data "terraform_remote_state" "local" {
+  backend = "local"
+  config = {
+    path = "../dns/terraform.tfstate"
+  }
+}
+
+data "terraform_remote_state" "external" {
+  backend = "s3"
+  config = {
+    bucket = "other"
+    key    = "network/terraform.tfstate"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "dns", "main.tf"), `provider "aws" {}`)
+
+	modules, err := DiscoverWithOptions(Options{WorkspaceAbs: workspace})
+	assert.NoError(t, err)
+
+	dependencies := InferDependencies(modules)
+	assert.Equal(t, map[string][]string{
+		"app":      {"database", "dns"},
+		"database": {"network"},
+		"dns":      nil,
+		"network":  nil,
+	}, dependencies)
+
+	merged := MergeDependencies(dependencies, map[string][]string{"dns": {"network"}})
+	assert.Equal(t, []string{"network"}, merged["dns"])
+	assert.Equal(t, []string{"database", "dns"}, merged["app"])
+}
+
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func TestGetAffectedModules_FromGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	workspace := t.TempDir()
+
+	writeTestFile(t, filepath.Join(workspace, "modules", "vpc", "main.tf"), `resource "aws_vpc" "this" {}`)
+	writeTestFile(t, filepath.Join(workspace, "modules", "subnets", "main.tf"), `
+module "vpc" {
+  source = "../vpc"
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "stacks", "network", "main.tf"), `
+provider "aws" {}
+
+module "subnets" {
+  source = "../../modules/subnets"
+}
+`)
+	writeTestFile(t, filepath.Join(workspace, "stacks", "app", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "stacks", "dns", "main.tf"), `provider "aws" {}`)
+	writeTestFile(t, filepath.Join(workspace, "envs", "dev.tfvars"), `name = "dev"`)
+
+	runTestGit(t, workspace, "init", "-q", "-b", "main")
+	runTestGit(t, workspace, "add", "-A")
+	runTestGit(t, workspace, "commit", "-q", "-m", "initial")
+	runTestGit(t, workspace, "checkout", "-q", "-b", "feature")
+
+	writeTestFile(t, filepath.Join(workspace, "modules", "vpc", "variables.tf"), `variable "cidr" {}`)
+	runTestGit(t, workspace, "add", "-A")
+	runTestGit(t, workspace, "commit", "-q", "-m", "change the vpc")
+
+	// An uncommitted change in a var file that lives outside of the module.
+	writeTestFile(t, filepath.Join(workspace, "envs", "dev.tfvars"), `name = "development"`)
+
+	changedFiles, err := GetChangedFiles(workspace, ChangesOptions{BaseRef: "main"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"envs/dev.tfvars", "modules/vpc/variables.tf"}, changedFiles)
+
+	committedOnly, err := GetChangedFiles(workspace, ChangesOptions{BaseRef: "main", IgnoreUncommitted: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"modules/vpc/variables.tf"}, committedOnly)
+
+	modules, err := DiscoverWithOptions(Options{WorkspaceAbs: workspace})
+	assert.NoError(t, err)
+
+	changeSet := &ChangeSet{
+		BaseRef:      "main",
+		ChangedFiles: changedFiles,
+		Affected:     GetAffectedModules(modules, changedFiles, map[string][]string{"stacks/app": {"envs/dev.tfvars"}}),
+	}
+
+	assert.Equal(t, []string{"modules/subnets", "modules/vpc", "stacks/app", "stacks/network"}, changeSet.GetModulePaths())
+
+	tfOptions := changeSet.GetTfOptions(terraformcore.TfOptions{TerraformVersion: "1.6.0"})
+	assert.Len(t, tfOptions, 2)
+	assert.Equal(t, "stacks/app", tfOptions[0].ModulePath)
+	assert.Equal(t, "1.6.0", tfOptions[1].TerraformVersion)
+
+	_, err = GetChangedFiles(workspace, ChangesOptions{BaseRef: "missing"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const terraformJSONExtension = ".tf.json"
+
+// hclBlock is a block of a terraform (or terragrunt) file, e.g. terraform { ... }, or module "x" { ... }.
+// Only the blocks and the attributes of hclFileSchema are read: the ones needed to discover the modules,
+// and how they relate to each other.
+type hclBlock struct {
+	Type   string
+	Labels []string
+	// Attributes are the attributes of the block. The expressions that evaluate to a string, a number or
+	// a bool without variables (e.g. string literals) are converted to a string, the other ones are kept
+	// as they're written.
+	Attributes map[string]string
+	Blocks     []hclBlock
+
+	expressions map[string]hcl.Expression
+	src         []byte
+}
+
+// hclBlockSchema describes the labels, attributes and nested blocks of a block to read.
+type hclBlockSchema struct {
+	labels     []string
+	attributes []string
+	// allAttributes reads every attribute of the block, e.g. the configuration of a backend.
+	allAttributes bool
+	blocks        map[string]hclBlockSchema
+}
+
+// hclFileSchema are the blocks and attributes read from the terraform and terragrunt files. The other
+// ones (e.g. resources or locals) are ignored.
+var hclFileSchema = hclBlockSchema{
+	attributes: []string{"terraform_version_constraint"},
+	blocks: map[string]hclBlockSchema{
+		"terraform": {
+			// source is the module of a terragrunt unit.
+			attributes: []string{"required_version", "source"},
+			blocks: map[string]hclBlockSchema{
+				"backend": {labels: []string{"type"}, allAttributes: true},
+				"cloud":   {},
+			},
+		},
+		"provider":     {labels: []string{"name"}},
+		"module":       {labels: []string{"name"}, attributes: []string{"source"}},
+		"data":         {labels: []string{"type", "name"}, attributes: []string{"backend", "config"}},
+		"remote_state": {attributes: []string{"backend"}},
+	},
+}
+
+func (s hclBlockSchema) getBodySchema() *hcl.BodySchema {
+	schema := &hcl.BodySchema{}
+	for _, name := range s.attributes {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+
+	for blockType, block := range s.blocks {
+		schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{Type: blockType, LabelNames: block.labels})
+	}
+
+	return schema
+}
+
+// getBlocks returns the nested blocks of the given type.
+func (b *hclBlock) getBlocks(blockType string) []hclBlock {
+	var blocks []hclBlock
+	for _, block := range b.Blocks {
+		if block.Type == blockType {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// getObject returns the attributes of an object attribute, e.g. config = { bucket = "x", key = "y" }.
+// Nested objects are kept as they're written. If the attribute isn't an object, it's empty.
+func (b *hclBlock) getObject(name string) map[string]string {
+	attributes := map[string]string{}
+
+	expr, ok := b.expressions[name]
+	if !ok {
+		return attributes
+	}
+
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return attributes
+	}
+
+	for _, pair := range pairs {
+		key := hcl.ExprAsKeyword(pair.Key)
+		if key == "" {
+			key = getHCLExpressionValue(pair.Key, b.src)
+		}
+
+		attributes[key] = getHCLExpressionValue(pair.Value, b.src)
+	}
+
+	return attributes
+}
+
+// parseHCLFile parses a terraform or terragrunt file (or a .tf.json file), and returns it as a block
+// with no type.
+func parseHCLFile(path string) (*hclBlock, error) {
+	parser := hclparse.NewParser()
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, terraformJSONExtension) {
+		file, diags = parser.ParseJSONFile(path)
+	} else {
+		file, diags = parser.ParseHCLFile(path)
+	}
+
+	if diags.HasErrors() {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError(fmt.Sprintf("unable to parse %s", path), diags)
+	}
+
+	return parseHCLBody("", nil, file.Body, hclFileSchema, file.Bytes)
+}
+
+// parseHCL parses the source of a terraform or terragrunt file, in the native syntax.
+func parseHCL(src string) (*hclBlock, error) {
+	file, diags := hclparse.NewParser().ParseHCL([]byte(src), "main.tf")
+	if diags.HasErrors() {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError("unable to parse the HCL source", diags)
+	}
+
+	return parseHCLBody("", nil, file.Body, hclFileSchema, file.Bytes)
+}
+
+func parseHCLBody(blockType string, labels []string, body hcl.Body, schema hclBlockSchema, src []byte) (*hclBlock, error) {
+	content, remain, diags := body.PartialContent(schema.getBodySchema())
+	if diags.HasErrors() {
+		return nil, erroer.NewErrDiscoveryInvalidArgumentError("unable to read the blocks of the HCL file", diags)
+	}
+
+	attributes := content.Attributes
+	if schema.allAttributes {
+		// The nested blocks (e.g. assume_role of a backend) aren't attributes, so their diagnostics are
+		// ignored.
+		remainAttributes, _ := remain.JustAttributes()
+		for name, attribute := range remainAttributes {
+			attributes[name] = attribute
+		}
+	}
+
+	block := &hclBlock{
+		Type:        blockType,
+		Labels:      labels,
+		Attributes:  map[string]string{},
+		expressions: map[string]hcl.Expression{},
+		src:         src,
+	}
+
+	for name, attribute := range attributes {
+		block.Attributes[name] = getHCLExpressionValue(attribute.Expr, src)
+		block.expressions[name] = attribute.Expr
+	}
+
+	for _, nested := range content.Blocks {
+		nestedBlock, err := parseHCLBody(nested.Type, nested.Labels, nested.Body, schema.blocks[nested.Type], src)
+		if err != nil {
+			return nil, err
+		}
+
+		block.Blocks = append(block.Blocks, *nestedBlock)
+	}
+
+	return block, nil
+}
+
+// getHCLExpressionValue returns the value of the expression as a string, if it can be evaluated without
+// variables or functions, and converted to a string. Otherwise, it's the expression as it's written.
+func getHCLExpressionValue(expr hcl.Expression, src []byte) string {
+	value, diags := expr.Value(nil)
+	if !diags.HasErrors() && value.IsWhollyKnown() && !value.IsNull() {
+		if str, err := convert.Convert(value, cty.String); err == nil {
+			return str.AsString()
+		}
+	}
+
+	return string(expr.Range().SliceBytes(src))
+}
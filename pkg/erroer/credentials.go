@@ -0,0 +1,24 @@
+package erroer
+
+import (
+	"fmt"
+)
+
+type ErrCredentialsNotFoundError struct {
+	BaseError // Embedding BaseError
+	// Provider is the name of the credentials provider, e.g. aws-static-keys
+	Provider string
+}
+
+const ErrCredentialsNotFoundErrorPrefix = "Credentials not found"
+
+// NewErrCredentialsNotFoundError creates a new ErrCredentialsNotFoundError, for a provider that can't resolve its credentials in the host.
+func NewErrCredentialsNotFoundError(provider, errMsg string, err error) *ErrCredentialsNotFoundError {
+	return &ErrCredentialsNotFoundError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s: %s", ErrCredentialsNotFoundErrorPrefix, provider, errMsg),
+		},
+		Provider: provider,
+	}
+}
@@ -0,0 +1,22 @@
+package erroer
+
+import (
+	"fmt"
+)
+
+type ErrDaggerEngineUnavailableError struct {
+	BaseError // Embedding BaseError
+}
+
+const ErrDaggerEngineUnavailableErrorPrefix = "Dagger engine unavailable"
+
+// NewErrDaggerEngineUnavailableError creates a new ErrDaggerEngineUnavailableError. It's returned when the Dagger engine can't be started,
+// or it doesn't respond; usually because there's no container runtime (e.g. docker) available.
+func NewErrDaggerEngineUnavailableError(errMsg string, err error) *ErrDaggerEngineUnavailableError {
+	return &ErrDaggerEngineUnavailableError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s", ErrDaggerEngineUnavailableErrorPrefix, errMsg),
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package erroer
+
+import (
+	"fmt"
+)
+
+type ErrDiscoveryInvalidArgumentError struct {
+	BaseError // Embedding BaseError
+}
+
+const ErrDiscoveryInvalidArgumentErrorPrefix = "Invalid argument while discovering the modules"
+
+// NewErrDiscoveryInvalidArgumentError creates a new ErrDiscoveryInvalidArgumentError. It utilizes the BaseError struct for common functionality.
+func NewErrDiscoveryInvalidArgumentError(errMsg string, err error) *ErrDiscoveryInvalidArgumentError {
+	return &ErrDiscoveryInvalidArgumentError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s", ErrDiscoveryInvalidArgumentErrorPrefix, errMsg),
+		},
+	}
+}
+
+type ErrDiscoveryGitError struct {
+	BaseError // Embedding BaseError
+}
+
+const ErrDiscoveryGitErrorPrefix = "Git error while detecting the changed modules"
+
+// NewErrDiscoveryGitError creates a new ErrDiscoveryGitError. It's returned when a git command fails, e.g. if the base ref doesn't exist.
+func NewErrDiscoveryGitError(errMsg string, err error) *ErrDiscoveryGitError {
+	return &ErrDiscoveryGitError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s", ErrDiscoveryGitErrorPrefix, errMsg),
+		},
+	}
+}
@@ -2,6 +2,7 @@ package erroer
 
 import (
 	"fmt"
+	"strings"
 )
 
 type ErrTerraDaggerInvalidArgumentError struct {
@@ -19,3 +20,83 @@ func NewErrTerraDaggerInvalidArgumentError(errMsg string, err error) *ErrTerraDa
 		},
 	}
 }
+
+type ErrTerraDaggerJobsFailedError struct {
+	BaseError // Embedding BaseError
+	// Failed are the IDs (e.g. the module paths) of the jobs that failed.
+	Failed []string
+}
+
+const ErrTerraDaggerJobsFailedErrorPrefix = "Jobs failed"
+
+// NewErrTerraDaggerJobsFailedError creates a new ErrTerraDaggerJobsFailedError, listing the jobs that failed. It wraps the first error.
+func NewErrTerraDaggerJobsFailedError(failed []string, err error) *ErrTerraDaggerJobsFailedError {
+	return &ErrTerraDaggerJobsFailedError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s", ErrTerraDaggerJobsFailedErrorPrefix, strings.Join(failed, ", ")),
+		},
+		Failed: failed,
+	}
+}
+
+type ErrTerraDaggerDependencyCycleError struct {
+	BaseError // Embedding BaseError
+	// Cycle is the path of the cycle, where the first and the last elements are the same.
+	Cycle []string
+}
+
+const ErrTerraDaggerDependencyCycleErrorPrefix = "Dependency cycle"
+
+// NewErrTerraDaggerDependencyCycleError creates a new ErrTerraDaggerDependencyCycleError, showing the path of the cycle (e.g. a -> b -> a).
+func NewErrTerraDaggerDependencyCycleError(cycle []string) *ErrTerraDaggerDependencyCycleError {
+	return &ErrTerraDaggerDependencyCycleError{
+		BaseError: BaseError{
+			ErrMsg: fmt.Sprintf("%s: %s", ErrTerraDaggerDependencyCycleErrorPrefix, strings.Join(cycle, " -> ")),
+		},
+		Cycle: cycle,
+	}
+}
+
+type ErrTerraDaggerServiceNotReadyError struct {
+	BaseError // Embedding BaseError
+	// Alias is the alias (hostname) of the service that isn't ready.
+	Alias string
+}
+
+const ErrTerraDaggerServiceNotReadyErrorPrefix = "Service not ready"
+
+// NewErrTerraDaggerServiceNotReadyError creates a new ErrTerraDaggerServiceNotReadyError, for a service that didn't start or didn't become ready.
+func NewErrTerraDaggerServiceNotReadyError(alias, errMsg string, err error) *ErrTerraDaggerServiceNotReadyError {
+	return &ErrTerraDaggerServiceNotReadyError{
+		BaseError: BaseError{
+			ErrWrapped: err,
+			ErrMsg:     fmt.Sprintf("%s: %s: %s", ErrTerraDaggerServiceNotReadyErrorPrefix, alias, errMsg),
+		},
+		Alias: alias,
+	}
+}
+
+type ErrTerraDaggerImageDigestMismatchError struct {
+	BaseError // Embedding BaseError
+	// Image is the image reference, e.g. hashicorp/terraform:1.7.5
+	Image string
+	// Expected is the digest recorded in the lock file.
+	Expected string
+	// Actual is the digest resolved from the registry.
+	Actual string
+}
+
+const ErrTerraDaggerImageDigestMismatchErrorPrefix = "Image digest mismatch"
+
+// NewErrTerraDaggerImageDigestMismatchError creates a new ErrTerraDaggerImageDigestMismatchError, for an image whose resolved digest isn't the one of the lock file.
+func NewErrTerraDaggerImageDigestMismatchError(image, expected, actual string) *ErrTerraDaggerImageDigestMismatchError {
+	return &ErrTerraDaggerImageDigestMismatchError{
+		BaseError: BaseError{
+			ErrMsg: fmt.Sprintf("%s: %s: the lock file has %s, but the registry resolved %s", ErrTerraDaggerImageDigestMismatchErrorPrefix, image, expected, actual),
+		},
+		Image:    image,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
@@ -1,6 +1,9 @@
 package erroer
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ErrTerraformCoreInvalidConfigurationError struct {
 	BaseError
@@ -31,3 +34,43 @@ func NewErrTerraformCoreInvalidArgumentError(errMsg string, err error) *ErrTerra
 		},
 	}
 }
+
+type ErrTerraformCoreGuardrailViolationError struct {
+	BaseError
+	Violations []string
+}
+
+const ErrTerraformCoreGuardrailViolationErrorPrefix = "Guardrails refused to run a destructive change"
+
+func NewErrTerraformCoreGuardrailViolationError(errMsg string, violations []string) *ErrTerraformCoreGuardrailViolationError {
+	return &ErrTerraformCoreGuardrailViolationError{
+		BaseError: BaseError{
+			ErrMsg: fmt.Sprintf("%s: %s: %s", ErrTerraformCoreGuardrailViolationErrorPrefix, errMsg, strings.Join(violations, "; ")),
+		},
+		Violations: violations,
+	}
+}
+
+type ErrTerraformCoreNotIdempotentError struct {
+	BaseError
+	// Resources are the resources that still show changes after the apply, e.g. "random_string.this (update)".
+	Resources []string
+}
+
+const ErrTerraformCoreNotIdempotentErrorPrefix = "The plan after the apply still shows changes"
+
+func NewErrTerraformCoreNotIdempotentError(resources []string) *ErrTerraformCoreNotIdempotentError {
+	errMsg := ErrTerraformCoreNotIdempotentErrorPrefix
+	if len(resources) > 0 {
+		errMsg = fmt.Sprintf("%s: %s", errMsg, strings.Join(resources, "; "))
+	} else {
+		errMsg = fmt.Sprintf("%s: only the outputs changed", errMsg)
+	}
+
+	return &ErrTerraformCoreNotIdempotentError{
+		BaseError: BaseError{
+			ErrMsg: errMsg,
+		},
+		Resources: resources,
+	}
+}
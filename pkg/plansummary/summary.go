@@ -0,0 +1,290 @@
+package plansummary
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionReplace = "replace"
+	ActionDestroy = "destroy"
+	ActionRead    = "read"
+
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatJSON     = "json"
+
+	// GitHubCommentMaxLength is the maximum number of characters allowed in a GitHub comment body.
+	GitHubCommentMaxLength = 65536
+
+	truncatedNotice = "\n... (truncated)"
+)
+
+// actionsOrder is the order in which the grouped changes are rendered.
+var actionsOrder = []string{ActionDestroy, ActionReplace, ActionUpdate, ActionCreate, ActionRead}
+
+// Summary is a renderable summary of a plan. Changes are grouped by action, and each group holds the
+// resource addresses.
+type Summary struct {
+	Module  string              `json:"module,omitempty"`
+	Counts  map[string]int      `json:"counts"`
+	Changes map[string][]string `json:"changes"`
+	Output  string              `json:"-"`
+}
+
+type Options struct {
+	// Title is the heading of the summary. Defaults to "Terraform plan"
+	Title string
+	// Module is the module (path) the plan belongs to, shown in the heading
+	Module string
+	// MaxLength is the maximum length of the rendered summary. Defaults to GitHubCommentMaxLength
+	MaxLength int
+	// HideFullOutput omits the full human-readable plan from the rendered summary
+	HideFullOutput bool
+}
+
+func (o *Options) getTitle() string {
+	if o.Title == "" {
+		return "Terraform plan"
+	}
+
+	return o.Title
+}
+
+func (o *Options) getMaxLength() int {
+	if o.MaxLength <= 0 {
+		return GitHubCommentMaxLength
+	}
+
+	return o.MaxLength
+}
+
+// New builds a summary from a plan result.
+func New(result *terraformcore.PlanResult, module string) (*Summary, error) {
+	if result == nil || result.Plan == nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError("the plan result cannot be nil", nil)
+	}
+
+	s := &Summary{
+		Module:  module,
+		Counts:  map[string]int{},
+		Changes: map[string][]string{},
+		Output:  result.Output,
+	}
+
+	for _, action := range actionsOrder {
+		s.Counts[action] = 0
+	}
+
+	for idx := range result.Plan.ResourceChanges {
+		rc := &result.Plan.ResourceChanges[idx]
+		action := getAction(rc)
+		if action == "" {
+			continue
+		}
+
+		s.Counts[action]++
+		s.Changes[action] = append(s.Changes[action], rc.Address)
+	}
+
+	for action := range s.Changes {
+		sort.Strings(s.Changes[action])
+	}
+
+	return s, nil
+}
+
+func getAction(rc *terraformcore.TfResourceChange) string {
+	switch {
+	case rc.IsReplace():
+		return ActionReplace
+	case rc.IsDelete():
+		return ActionDestroy
+	case rc.IsNoOp():
+		return ""
+	}
+
+	for _, a := range rc.Change.Actions {
+		switch a {
+		case ActionCreate, ActionUpdate, ActionRead:
+			return a
+		}
+	}
+
+	return ""
+}
+
+// HasChanges returns true if the plan creates, updates, replaces or destroys any resource.
+func (s *Summary) HasChanges() bool {
+	return s.Counts[ActionCreate]+s.Counts[ActionUpdate]+s.Counts[ActionReplace]+s.Counts[ActionDestroy] > 0
+}
+
+func (s *Summary) countsLine() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to replace, %d to destroy, %d to read",
+		s.Counts[ActionCreate], s.Counts[ActionUpdate], s.Counts[ActionReplace], s.Counts[ActionDestroy], s.Counts[ActionRead])
+}
+
+func (s *Summary) heading(opts *Options) string {
+	module := opts.Module
+	if module == "" {
+		module = s.Module
+	}
+
+	if module == "" {
+		return opts.getTitle()
+	}
+
+	return fmt.Sprintf("%s: %s", opts.getTitle(), module)
+}
+
+// Markdown renders the summary as Markdown, ready to be posted as a pull-request comment. The full
+// plan goes into a collapsible section, and it's truncated first when the summary exceeds the max length.
+func (s *Summary) Markdown(opts Options) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %s\n\n", s.heading(&opts)))
+	if !s.HasChanges() {
+		sb.WriteString("**No changes.** Your infrastructure matches the configuration.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("**Plan:** %s\n", s.countsLine()))
+	}
+
+	for _, action := range actionsOrder {
+		addresses := s.Changes[action]
+		if len(addresses) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\n#### %s (%d)\n\n", strings.ToUpper(action[:1])+action[1:], len(addresses)))
+		for _, address := range addresses {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", address))
+		}
+	}
+
+	summary := sb.String()
+	maxLength := opts.getMaxLength()
+
+	if opts.HideFullOutput || s.Output == "" {
+		return truncate(summary, maxLength)
+	}
+
+	output := strings.TrimSpace(s.Output)
+	fence := getCodeFence(output)
+	detailsOpen := "\n<details><summary>Show full plan</summary>\n\n" + fence + "hcl\n"
+	detailsClose := "\n" + fence + "\n\n</details>\n"
+
+	available := maxLength - len(summary) - len(detailsOpen) - len(detailsClose)
+	if available <= len(truncatedNotice) {
+		return truncate(summary, maxLength)
+	}
+
+	return summary + detailsOpen + truncate(output, available) + detailsClose
+}
+
+// getCodeFence returns a code fence longer than the longest run of backticks of the content (e.g. in a
+// heredoc or a description of the plan), so the content can't close it.
+func getCodeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r != '`' {
+			run = 0
+			continue
+		}
+
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if longest < 3 {
+		return "```"
+	}
+
+	return strings.Repeat("`", longest+1)
+}
+
+// Text renders the summary as plain text.
+func (s *Summary) Text(opts Options) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s\n", s.heading(&opts)))
+	if !s.HasChanges() {
+		sb.WriteString("No changes.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Plan: %s\n", s.countsLine()))
+	}
+
+	for _, action := range actionsOrder {
+		for _, address := range s.Changes[action] {
+			sb.WriteString(fmt.Sprintf("  %-8s %s\n", action, address))
+		}
+	}
+
+	if !opts.HideFullOutput && s.Output != "" {
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimSpace(s.Output))
+		sb.WriteString("\n")
+	}
+
+	return truncate(sb.String(), opts.getMaxLength())
+}
+
+// JSON renders the summary (counts and grouped changes) as JSON.
+func (s *Summary) JSON() (string, error) {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// Render renders the summary in the given format (markdown, text or json).
+func Render(result *terraformcore.PlanResult, format string, opts Options) (string, error) {
+	s, err := New(result, opts.Module)
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "md", "":
+		return s.Markdown(opts), nil
+	case FormatText, "txt":
+		return s.Text(opts), nil
+	case FormatJSON:
+		return s.JSON()
+	default:
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the format %s is not supported, use one of: %s, %s, %s", format, FormatMarkdown, FormatText, FormatJSON), nil)
+	}
+}
+
+func truncate(content string, maxLength int) string {
+	if len(content) <= maxLength {
+		return content
+	}
+
+	cut := maxLength - len(truncatedNotice)
+	if cut < 0 {
+		cut = 0
+	}
+
+	// Don't split a line in half, if possible, and never a multi-byte character.
+	if idx := strings.LastIndex(content[:cut], "\n"); idx > 0 {
+		cut = idx
+	}
+
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+
+	return content[:cut] + truncatedNotice
+}
@@ -0,0 +1,99 @@
+package plansummary
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestPlanResult(t *testing.T, output string) *terraformcore.PlanResult {
+	t.Helper()
+	plan, err := terraformcore.ParsePlanJSON(`{
+  "resource_changes": [
+    {"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "change": {"actions": ["delete"]}},
+    {"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["create", "delete"]}},
+    {"address": "aws_iam_role.ci", "type": "aws_iam_role", "change": {"actions": ["update"]}},
+    {"address": "random_string.b", "type": "random_string", "change": {"actions": ["create"]}},
+    {"address": "random_string.a", "type": "random_string", "change": {"actions": ["create"]}},
+    {"address": "null_resource.noop", "type": "null_resource", "change": {"actions": ["no-op"]}}
+  ]
+}`)
+	assert.NoError(t, err)
+
+	return &terraformcore.PlanResult{Plan: plan, Output: output}
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(getTestPlanResult(t, ""), "modules/app")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, s.Counts[ActionCreate])
+	assert.Equal(t, 1, s.Counts[ActionUpdate])
+	assert.Equal(t, 1, s.Counts[ActionReplace])
+	assert.Equal(t, 1, s.Counts[ActionDestroy])
+	assert.Equal(t, []string{"random_string.a", "random_string.b"}, s.Changes[ActionCreate])
+	assert.True(t, s.HasChanges())
+
+	_, err = New(nil, "")
+	assert.Error(t, err)
+}
+
+func TestSummary_Markdown(t *testing.T) {
+	s, err := New(getTestPlanResult(t, "  # aws_s3_bucket.logs will be destroyed"), "modules/app")
+	assert.NoError(t, err)
+
+	md := s.Markdown(Options{})
+	assert.Contains(t, md, "### Terraform plan: modules/app")
+	assert.Contains(t, md, "**Plan:** 2 to add, 1 to change, 1 to replace, 1 to destroy, 0 to read")
+	assert.Contains(t, md, "#### Replace (1)\n\n- `aws_instance.web`")
+	assert.Contains(t, md, "<details><summary>Show full plan</summary>")
+	assert.Contains(t, md, "aws_s3_bucket.logs will be destroyed")
+}
+
+func TestSummary_Markdown_Truncated(t *testing.T) {
+	s, err := New(getTestPlanResult(t, strings.Repeat("  # some resource will be created\n", 1000)), "")
+	assert.NoError(t, err)
+
+	md := s.Markdown(Options{MaxLength: 2000})
+	assert.LessOrEqual(t, len(md), 2000)
+	assert.Contains(t, md, truncatedNotice)
+	assert.True(t, strings.HasSuffix(md, "</details>\n"))
+}
+
+func TestSummary_Markdown_Backticks(t *testing.T) {
+	s, err := New(getTestPlanResult(t, "  + description = <<-EOT\n        ```hcl\n        ````\n    EOT"), "")
+	assert.NoError(t, err)
+
+	md := s.Markdown(Options{})
+	assert.Contains(t, md, "\n`````hcl\n")
+	assert.True(t, strings.HasSuffix(md, "\n`````\n\n</details>\n"))
+}
+
+func TestTruncate_Runes(t *testing.T) {
+	out := truncate(strings.Repeat("é", 100), 51)
+	assert.True(t, utf8.ValidString(out))
+	assert.LessOrEqual(t, len(out), 51)
+	assert.True(t, strings.HasSuffix(out, truncatedNotice))
+}
+
+func TestRender(t *testing.T) {
+	result := getTestPlanResult(t, "")
+
+	out, err := Render(result, FormatJSON, Options{})
+	assert.NoError(t, err)
+
+	var s Summary
+	assert.NoError(t, json.Unmarshal([]byte(out), &s))
+	assert.Equal(t, 1, s.Counts[ActionDestroy])
+
+	out, err = Render(result, FormatText, Options{Title: "Plan"})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "destroy  aws_s3_bucket.logs")
+
+	_, err = Render(result, "yaml", Options{})
+	assert.Error(t, err)
+}
@@ -2,6 +2,12 @@ package terradagger
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
 
 	"github.com/Excoriate/go-terradagger/pkg/utils"
 
@@ -12,15 +18,45 @@ import (
 	"github.com/Excoriate/go-terradagger/pkg/logger"
 )
 
+// defaultInterruptGracePeriod is the time given to terraform to finish gracefully, before the context deadline.
+const defaultInterruptGracePeriod = 30 * time.Second
+
+const (
+	// InterruptsDir is the directory of the containers where the interrupt markers are created. It's a
+	// cache volume (InterruptsCacheVolume) shared by the containers of the engine, so a marker created
+	// by one container is seen by the interruptible commands running in the other ones.
+	InterruptsDir = "/terradagger/interrupts"
+	// InterruptsCacheVolume is the cache volume mounted in InterruptsDir.
+	InterruptsCacheVolume = "terradagger-interrupts"
+)
+
 type Options struct {
 	Workspace     string
 	EnvVars       map[string]string
 	ExcludeDirs   []string
 	ExcludedFiles []string
+	// InterruptGracePeriod is the time before the context deadline at which a running apply or destroy
+	// is interrupted (SIGINT), so terraform can stop gracefully and release the state lock. When the
+	// context is cancelled without a deadline, it's the time given to terraform to stop after the SIGINT.
+	// Defaults to 30s
+	InterruptGracePeriod time.Duration
+	// LogOutput is where the Dagger engine logs are written. Defaults to os.Stdout.
+	LogOutput io.Writer
+	// EngineWorkdir is the host directory used by the Dagger engine as its working directory.
+	EngineWorkdir string
+	// Verbosity controls whether the Dagger engine logs are written (daggerx.VerbosityDefault) or discarded (daggerx.VerbosityQuiet).
+	Verbosity daggerx.Verbosity
+	// ConnectTimeout is the maximum time to wait for the Dagger engine to start. Zero means no timeout.
+	ConnectTimeout time.Duration
+	// RunnerHost overrides the Dagger engine to connect to, e.g. tcp://dagger-engine:1234
+	RunnerHost string
 }
 
 type Client interface {
 	StartEngine() error
+	Ping() error
+	Close() error
+	Run(fn func(td *TD) error) error
 }
 
 type TD struct {
@@ -29,27 +65,182 @@ type TD struct {
 	Engine daggerx.Engine
 	Config config.Config
 	ID     string
+
+	cancel               context.CancelFunc
+	closeOnce            sync.Once
+	closeMu              sync.Mutex
+	closeHooks           []func() error
+	parent               *TD
+	interruptID          string
+	interruptGracePeriod time.Duration
 }
 
 func New(ctx context.Context, options *Options) *TD {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tdCtx, cancel := context.WithCancel(ctx)
+
 	td := &TD{
-		Log: logger.NewLogger().Logger,
-		Ctx: ctx,
-		ID:  utils.GetUUID(),
+		Log:         logger.NewLogger().Logger,
+		Ctx:         tdCtx,
+		ID:          utils.GetUUID(),
+		cancel:      cancel,
+		interruptID: utils.GetUUID(),
+	}
+
+	td.interruptGracePeriod = options.InterruptGracePeriod
+	if td.interruptGracePeriod <= 0 {
+		td.interruptGracePeriod = defaultInterruptGracePeriod
 	}
 
-	td.Engine = daggerx.New(td.Log)
+	td.Engine = daggerx.NewWithOptions(td.Log, daggerx.Options{
+		LogOutput:      options.LogOutput,
+		Workdir:        options.EngineWorkdir,
+		Verbosity:      options.Verbosity,
+		ConnectTimeout: options.ConnectTimeout,
+		RunnerHost:     options.RunnerHost,
+	})
 	td.Config = config.New(options.Workspace, options.EnvVars, options.ExcludeDirs, options.ExcludedFiles)
 
 	return td
 }
 
-// StartEngine starts the Dagger engine.
+// StartEngine starts the Dagger engine, and checks that it responds.
 func (td *TD) StartEngine() error {
-	_, err := td.Engine.Start(td.Ctx)
+	// The engine session isn't ended by the cancellation of the context, so the interruptible commands
+	// can still stop gracefully (see RunGracefully). Close ends it.
+	_, err := td.Engine.Start(context.WithoutCancel(td.Ctx))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return td.Ping()
+}
+
+// Ping checks that the Dagger engine is started and responds.
+func (td *TD) Ping() error {
+	return td.Engine.Ping(td.Ctx)
+}
+
+// OnClose registers a function that runs when the terradagger session is closed, after the context is
+// cancelled and before the Dagger engine is closed, e.g. to stop the services started in the session.
+// The functions run in the reverse order of their registration. On the TD of an orchestrated job, they
+// run when the TD that runs the jobs is closed.
+func (td *TD) OnClose(fn func() error) {
+	if td.parent != nil {
+		td.parent.OnClose(fn)
+		return
+	}
+
+	td.closeMu.Lock()
+	defer td.closeMu.Unlock()
+
+	td.closeHooks = append(td.closeHooks, fn)
+}
+
+// Close cancels the terradagger context, so any command still running is cancelled, runs the functions
+// registered with OnClose, and then closes the Dagger engine. It's safe to call it more than once.
+func (td *TD) Close() error {
+	var err error
+	td.closeOnce.Do(func() {
+		td.cancel()
+
+		td.closeMu.Lock()
+		hooks := td.closeHooks
+		td.closeHooks = nil
+		td.closeMu.Unlock()
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			errs = append(errs, hooks[i]())
+		}
+
+		errs = append(errs, td.Engine.Close())
+		err = errors.Join(errs...)
+	})
+
+	return err
+}
+
+// Run starts the engine (if it's not started yet), runs the function, and guarantees that the engine
+// is closed afterward, even if the function fails or panics.
+func (td *TD) Run(fn func(td *TD) error) (err error) {
+	if td.Engine.GetEngine() == nil {
+		if startErr := td.StartEngine(); startErr != nil {
+			return startErr
+		}
+	}
+
+	defer func() {
+		if closeErr := td.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	return fn(td)
+}
+
+// GetInterruptAt returns when a long-running command (apply, destroy) is interrupted gracefully: the
+// context deadline minus the grace period. The zero time means that there's no deadline.
+func (td *TD) GetInterruptAt() time.Time {
+	deadline, ok := td.Ctx.Deadline()
+	if !ok {
+		return time.Time{}
+	}
+
+	return deadline.Add(-td.interruptGracePeriod)
+}
+
+// GetInterruptMarkerFile returns the file (in InterruptsDir) that's created when the terradagger context
+// is cancelled while an interruptible command runs (see RunGracefully). The commands built with
+// BuildInterruptibleCMDWithSH watch it, and interrupt terraform (SIGINT) once it exists.
+func (td *TD) GetInterruptMarkerFile() string {
+	return path.Join(InterruptsDir, td.interruptID)
+}
+
+// RunGracefully runs fn, e.g. a container with an interruptible apply, with a context that outlives the
+// cancellation of the terradagger context: when it's cancelled without a deadline, interrupt is called
+// to create the interrupt marker file, so terraform receives a SIGINT, and fn has the grace period to
+// return before its context is cancelled too. When the deadline of the context is exceeded, the SIGINT
+// was already sent before it (see GetInterruptAt), so the context of fn is cancelled right away.
+func (td *TD) RunGracefully(fn func(ctx context.Context) error, interrupt func(ctx context.Context) error) error {
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(td.Ctx))
+	defer cancelRun()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-td.Ctx.Done():
+		}
+
+		if errors.Is(td.Ctx.Err(), context.DeadlineExceeded) {
+			cancelRun()
+			return
+		}
+
+		td.Log.Warn(fmt.Sprintf("the context was cancelled, interrupting the running commands gracefully (SIGINT), they have %s to stop", td.interruptGracePeriod))
+
+		grace := time.NewTimer(td.interruptGracePeriod)
+		defer grace.Stop()
+
+		if err := interrupt(runCtx); err != nil {
+			td.Log.Warn(fmt.Sprintf("unable to interrupt the running commands gracefully: %s", err))
+			cancelRun()
+			return
+		}
+
+		select {
+		case <-done:
+		case <-grace.C:
+			cancelRun()
+		}
+	}()
+
+	return fn(runCtx)
 }
@@ -0,0 +1,181 @@
+package terradagger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"dagger.io/dagger"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEngine records the calls of the terradagger client, without a Dagger engine.
+type fakeEngine struct {
+	td              *TD
+	started         bool
+	closed          int
+	cancelledOnStop bool
+}
+
+func (e *fakeEngine) Start(_ context.Context, _ ...dagger.ClientOpt) (*dagger.Client, error) {
+	e.started = true
+	return nil, nil
+}
+
+func (e *fakeEngine) GetEngine() *dagger.Client { return nil }
+
+func (e *fakeEngine) Ping(_ context.Context) error { return nil }
+
+func (e *fakeEngine) Close() error {
+	e.closed++
+	e.cancelledOnStop = e.td.Ctx.Err() != nil
+	return nil
+}
+
+func newTDWithFakeEngine(ctx context.Context) (*TD, *fakeEngine) {
+	td := New(ctx, &Options{})
+	engine := &fakeEngine{td: td}
+	td.Engine = engine
+
+	return td, engine
+}
+
+func TestTD_Close(t *testing.T) {
+	td, engine := newTDWithFakeEngine(context.Background())
+
+	assert.NoError(t, td.Close())
+	assert.NoError(t, td.Close())
+	assert.Equal(t, 1, engine.closed)
+	assert.True(t, engine.cancelledOnStop, "the context must be cancelled before the engine is closed")
+}
+
+func TestTD_Close_Hooks(t *testing.T) {
+	td, engine := newTDWithFakeEngine(context.Background())
+
+	var order []string
+	td.OnClose(func() error {
+		order = append(order, "first")
+		return nil
+	})
+	td.OnClose(func() error {
+		order = append(order, "second")
+		assert.Equal(t, 0, engine.closed, "the hooks must run before the engine is closed")
+		return errors.New("unable to stop the service")
+	})
+
+	assert.EqualError(t, td.Close(), "unable to stop the service")
+	assert.Equal(t, []string{"second", "first"}, order)
+	assert.Equal(t, 1, engine.closed)
+
+	assert.NoError(t, td.Close())
+	assert.Equal(t, []string{"second", "first"}, order)
+}
+
+func TestTD_Close_HooksOfTheJobs(t *testing.T) {
+	td, _ := newTDWithFakeEngine(context.Background())
+
+	var closed []string
+	_, err := td.RunJobs([]Job{
+		{ID: "network", Run: func(jobTD *TD) (string, error) {
+			jobTD.OnClose(func() error {
+				closed = append(closed, "network")
+				return nil
+			})
+			return "", nil
+		}},
+	}, OrchestratorOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, closed, "the hooks of the jobs must run when the TD is closed, not when the jobs finish")
+
+	assert.NoError(t, td.Close())
+	assert.Equal(t, []string{"network"}, closed)
+}
+
+func TestTD_Run(t *testing.T) {
+	td, engine := newTDWithFakeEngine(context.Background())
+
+	err := td.Run(func(td *TD) error { return errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+	assert.True(t, engine.started)
+	assert.Equal(t, 1, engine.closed)
+
+	td, engine = newTDWithFakeEngine(context.Background())
+	assert.Panics(t, func() {
+		_ = td.Run(func(td *TD) error { panic("boom") })
+	})
+	assert.Equal(t, 1, engine.closed)
+}
+
+func TestTD_GetInterruptAt(t *testing.T) {
+	td := New(context.Background(), &Options{})
+	assert.True(t, td.GetInterruptAt().IsZero())
+
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	td = New(ctx, &Options{InterruptGracePeriod: time.Minute})
+	assert.True(t, td.GetInterruptAt().Equal(deadline.Add(-time.Minute)))
+}
+
+func TestTD_RunGracefully(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	td := New(ctx, &Options{InterruptGracePeriod: 5 * time.Second})
+
+	interrupted := make(chan struct{})
+	start := time.Now()
+
+	// The command stops once it's interrupted, like terraform on SIGINT: its context isn't cancelled
+	// with the terradagger one.
+	err := td.RunGracefully(func(runCtx context.Context) error {
+		cancel()
+
+		select {
+		case <-interrupted:
+			assert.NoError(t, runCtx.Err())
+			return errors.New("interrupted")
+		case <-runCtx.Done():
+			return runCtx.Err()
+		}
+	}, func(_ context.Context) error {
+		close(interrupted)
+		return nil
+	})
+
+	assert.EqualError(t, err, "interrupted")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTD_RunGracefully_GracePeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	td := New(ctx, &Options{InterruptGracePeriod: 100 * time.Millisecond})
+
+	// The command ignores the interrupt: its context is cancelled after the grace period.
+	err := td.RunGracefully(func(runCtx context.Context) error {
+		cancel()
+		<-runCtx.Done()
+		return runCtx.Err()
+	}, func(_ context.Context) error { return nil })
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTD_RunGracefully_NotCancelled(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	err := td.RunGracefully(func(_ context.Context) error { return nil }, func(_ context.Context) error {
+		t.Error("the commands must not be interrupted if the context isn't cancelled")
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestTD_GetInterruptMarkerFile(t *testing.T) {
+	td := New(context.Background(), &Options{})
+	assert.True(t, strings.HasPrefix(td.GetInterruptMarkerFile(), InterruptsDir+"/"))
+
+	jobsTD := td.withContext(td.Ctx, func() {})
+	assert.NotEqual(t, td.GetInterruptMarkerFile(), jobsTD.GetInterruptMarkerFile())
+}
@@ -1,6 +1,10 @@
 package terradagger
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 const (
 	BashEntrypoint = "bash"
@@ -80,3 +84,51 @@ func BuildCMDWithBash(command string) []string {
 func BuildCMDWithSH(command string) []string {
 	return []string{ShEntrypoint, "-c", command}
 }
+
+// BuildInterruptibleCMDWithSH runs the command in the background of the shell, and forwards any SIGINT
+// or SIGTERM received by the shell to it as SIGINT, waiting for it to finish. Terraform handles SIGINT
+// gracefully: it stops after the in-flight operations, persists the state and releases the state lock.
+// Background jobs of a non-interactive shell start with SIGINT ignored, but Go binaries such as terraform
+// and terragrunt re-enable it when they register their own handler.
+// If interruptAt isn't zero, the command is also interrupted (SIGINT) at that time. The remaining time
+// is computed when the command starts, so the time spent pulling the image and running the previous
+// steps is taken into account.
+// If interruptMarkerFile isn't empty, the command is also interrupted (SIGINT) once that file exists:
+// it's created by TD.RunGracefully when the terradagger context is cancelled (see TD.GetInterruptMarkerFile).
+func BuildInterruptibleCMDWithSH(command string, interruptAt time.Time, interruptMarkerFile string) []string {
+	var sb strings.Builder
+
+	sb.WriteString(command + " & pid=$!; ")
+	sb.WriteString(`trap 'kill -INT "$pid" 2>/dev/null' INT TERM; `)
+
+	if !interruptAt.IsZero() {
+		sb.WriteString(fmt.Sprintf(`remaining=$((%d - $(date +%%s))); if [ "$remaining" -lt 1 ]; then remaining=1; fi; `, interruptAt.Unix()))
+		sb.WriteString(`(sleep "$remaining"; kill -INT "$pid" 2>/dev/null) & watchdog=$!; `)
+	}
+
+	if interruptMarkerFile != "" {
+		sb.WriteString(fmt.Sprintf(`(while [ ! -e '%s' ]; do sleep 1; done; kill -INT "$pid" 2>/dev/null) & watcher=$!; `, interruptMarkerFile))
+	}
+
+	// If the wait is interrupted by a trapped signal, wait again until the command finishes.
+	sb.WriteString(`wait "$pid"; status=$?; `)
+	sb.WriteString(`if kill -0 "$pid" 2>/dev/null; then wait "$pid"; status=$?; fi; `)
+
+	if !interruptAt.IsZero() {
+		sb.WriteString(`kill "$watchdog" 2>/dev/null; `)
+	}
+
+	if interruptMarkerFile != "" {
+		sb.WriteString(`kill "$watcher" 2>/dev/null; `)
+	}
+
+	sb.WriteString(`exit $status`)
+
+	return BuildCMDWithSH(sb.String())
+}
+
+// IsInterruptibleCMD returns true if the command was built by BuildInterruptibleCMDWithSH with an
+// interrupt marker file, so the container that runs it needs the interrupts directory.
+func IsInterruptibleCMD(cmd []string) bool {
+	return strings.Contains(strings.Join(cmd, " "), InterruptsDir+"/")
+}
@@ -0,0 +1,116 @@
+package terradagger
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// interruptExitCode is the exit code of the helper process when it's interrupted, like terraform.
+const interruptExitCode = 130
+
+// TestHelperProcess isn't a real test: it's the command run by the interruptible shell, which behaves
+// like terraform on SIGINT (it re-enables the signal, and exits gracefully).
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("TERRADAGGER_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+
+	select {
+	case <-interrupted:
+		os.Exit(interruptExitCode)
+	case <-time.After(10 * time.Second):
+		os.Exit(0)
+	}
+}
+
+func runInterruptible(t *testing.T, command string, interruptAt time.Time, interruptMarkerFile string) (int, time.Duration) {
+	t.Helper()
+
+	args := BuildInterruptibleCMDWithSH(command, interruptAt, interruptMarkerFile)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "TERRADAGGER_HELPER_PROCESS=1")
+
+	start := time.Now()
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), time.Since(start)
+	}
+
+	assert.NoError(t, err)
+	return 0, time.Since(start)
+}
+
+func helperCommand() string {
+	return "'" + os.Args[0] + "' -test.run=TestHelperProcess"
+}
+
+func TestBuildInterruptibleCMDWithSH_ExitStatus(t *testing.T) {
+	code, _ := runInterruptible(t, "exit 3", time.Time{}, "")
+	assert.Equal(t, 3, code)
+
+	args := BuildInterruptibleCMDWithSH("terraform apply", time.Time{}, "")
+	assert.NotContains(t, args[2], "watchdog")
+	assert.NotContains(t, args[2], "watcher")
+	assert.False(t, IsInterruptibleCMD(args))
+}
+
+func TestBuildInterruptibleCMDWithSH_InterruptAt(t *testing.T) {
+	// The deadline already passed when the command starts, so it's interrupted right away.
+	code, elapsed := runInterruptible(t, helperCommand(), time.Now().Add(-time.Minute), "")
+	assert.Equal(t, interruptExitCode, code)
+	assert.Less(t, elapsed, 5*time.Second)
+
+	// The remaining time is computed when the command starts, not when it's built.
+	interruptAt := time.Now().Add(2 * time.Second)
+	args := BuildInterruptibleCMDWithSH("terraform apply", interruptAt, "")
+	assert.True(t, strings.Contains(args[2], "$(date +%s)"))
+
+	code, elapsed = runInterruptible(t, helperCommand(), interruptAt, "")
+	assert.Equal(t, interruptExitCode, code)
+	assert.Less(t, elapsed, 5*time.Second)
+}
+
+func TestBuildInterruptibleCMDWithSH_InterruptMarker(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "interrupted")
+
+	// The marker is created while the command runs, e.g. when the context is cancelled.
+	time.AfterFunc(time.Second, func() {
+		_ = os.WriteFile(marker, nil, 0o600)
+	})
+
+	code, elapsed := runInterruptible(t, helperCommand(), time.Time{}, marker)
+	assert.Equal(t, interruptExitCode, code)
+	assert.Less(t, elapsed, 5*time.Second)
+
+	args := BuildInterruptibleCMDWithSH("terraform apply", time.Time{}, path.Join(InterruptsDir, "abc"))
+	assert.True(t, IsInterruptibleCMD(args))
+}
+
+func TestBuildInterruptibleCMDWithSH_ForwardsSignals(t *testing.T) {
+	args := BuildInterruptibleCMDWithSH(helperCommand(), time.Time{}, "")
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "TERRADAGGER_HELPER_PROCESS=1")
+	assert.NoError(t, cmd.Start())
+
+	// Give the helper process the time to register its handler.
+	time.Sleep(500 * time.Millisecond)
+	assert.NoError(t, cmd.Process.Signal(os.Interrupt))
+
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, cmd.Wait(), &exitErr)
+	assert.Equal(t, interruptExitCode, exitErr.ExitCode())
+}
@@ -0,0 +1,150 @@
+package terradagger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// DAG is the dependency graph of a set of jobs (e.g. root modules), keyed by job ID.
+type DAG struct {
+	dependencies map[string][]string
+	dependents   map[string][]string
+}
+
+// NewDAG builds the graph. Every job must be a key of the dependencies map (with no dependencies, if
+// it doesn't have any), and every dependency must be a job.
+func NewDAG(dependencies map[string][]string) (*DAG, error) {
+	dag := &DAG{
+		dependencies: map[string][]string{},
+		dependents:   map[string][]string{},
+	}
+
+	for id, deps := range dependencies {
+		seen := map[string]bool{}
+		for _, dep := range deps {
+			if _, ok := dependencies[dep]; !ok {
+				return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("%s depends on %s, which is unknown", id, dep), nil)
+			}
+
+			if seen[dep] {
+				continue
+			}
+
+			seen[dep] = true
+			dag.dependencies[id] = append(dag.dependencies[id], dep)
+			dag.dependents[dep] = append(dag.dependents[dep], id)
+		}
+
+		if _, ok := dag.dependencies[id]; !ok {
+			dag.dependencies[id] = nil
+		}
+	}
+
+	return dag, nil
+}
+
+// GetDependencies returns the jobs the given job depends on.
+func (d *DAG) GetDependencies(id string) []string {
+	return d.dependencies[id]
+}
+
+// GetDependents returns the jobs that depend on the given job.
+func (d *DAG) GetDependents(id string) []string {
+	return d.dependents[id]
+}
+
+// Levels sorts the jobs topologically, grouped in levels: the jobs of a level only depend on the jobs
+// of the previous levels, so they can run concurrently. The IDs of each level are sorted.
+func (d *DAG) Levels() ([][]string, error) {
+	pending := map[string]int{}
+	for id, deps := range d.dependencies {
+		pending[id] = len(deps)
+	}
+
+	var levels [][]string
+	for len(pending) > 0 {
+		var level []string
+		for id, count := range pending {
+			if count == 0 {
+				level = append(level, id)
+			}
+		}
+
+		if len(level) == 0 {
+			return nil, erroer.NewErrTerraDaggerDependencyCycleError(d.findCycle(pending))
+		}
+
+		sort.Strings(level)
+		for _, id := range level {
+			delete(pending, id)
+			for _, dependent := range d.dependents[id] {
+				pending[dependent]--
+			}
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// Sort returns the jobs in an order where every job comes after its dependencies.
+func (d *DAG) Sort() ([]string, error) {
+	levels, err := d.Levels()
+	if err != nil {
+		return nil, err
+	}
+
+	var sorted []string
+	for _, level := range levels {
+		sorted = append(sorted, level...)
+	}
+
+	return sorted, nil
+}
+
+// findCycle returns a cycle among the jobs that couldn't be sorted, e.g. [a b a].
+func (d *DAG) findCycle(pending map[string]int) []string {
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	// Every pending job has at least one pending dependency, so following them always ends in a cycle.
+	visited := map[string]int{}
+	var path []string
+
+	id := ids[0]
+	for {
+		if start, ok := visited[id]; ok {
+			return append(path[start:], id)
+		}
+
+		visited[id] = len(path)
+		path = append(path, id)
+
+		deps := append([]string{}, d.dependencies[id]...)
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			if _, ok := pending[dep]; ok {
+				id = dep
+				break
+			}
+		}
+	}
+}
+
+// Reverse returns the levels in reverse order, e.g. to destroy the dependents before their dependencies.
+func Reverse(levels [][]string) [][]string {
+	reversed := make([][]string, 0, len(levels))
+	for i := len(levels) - 1; i >= 0; i-- {
+		reversed = append(reversed, levels[i])
+	}
+
+	return reversed
+}
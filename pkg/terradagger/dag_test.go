@@ -0,0 +1,49 @@
+package terradagger
+
+import (
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDAG_Levels(t *testing.T) {
+	dag, err := NewDAG(map[string][]string{
+		"app":      {"network", "database"},
+		"database": {"network"},
+		"network":  nil,
+		"dns":      nil,
+	})
+	assert.NoError(t, err)
+
+	levels, err := dag.Levels()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"dns", "network"}, {"database"}, {"app"}}, levels)
+	assert.Equal(t, [][]string{{"app"}, {"database"}, {"dns", "network"}}, Reverse(levels))
+
+	sorted, err := dag.Sort()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dns", "network", "database", "app"}, sorted)
+}
+
+func TestDAG_Cycle(t *testing.T) {
+	dag, err := NewDAG(map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+		"d": nil,
+	})
+	assert.NoError(t, err)
+
+	_, err = dag.Levels()
+
+	var cycleErr *erroer.ErrTerraDaggerDependencyCycleError
+	assert.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{"a", "b", "c", "a"}, cycleErr.Cycle)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+}
+
+func TestNewDAG_UnknownDependency(t *testing.T) {
+	_, err := NewDAG(map[string][]string{"a": {"missing"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,271 @@
+package terradagger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
+)
+
+// defaultConcurrency is the number of jobs that run at the same time, if it's not set.
+const defaultConcurrency = 4
+
+// Job is a unit of work (e.g. a terraform plan of a module) run by the orchestrator.
+type Job struct {
+	// ID identifies the job in the results, e.g. the module path.
+	ID string
+	// Run runs the job. The TD shares the Dagger client, but its context is cancelled when the
+	// orchestrator stops (fail-fast mode), so the job should use it instead of the parent one.
+	Run func(td *TD) (string, error)
+}
+
+type OrchestratorOptions struct {
+	// Concurrency is the maximum number of jobs running at the same time. Defaults to 4.
+	Concurrency int
+	// ContinueOnError runs all the jobs, even if some of them fail. By default, the orchestrator stops
+	// at the first failure: the running jobs are cancelled, and the pending ones are skipped.
+	ContinueOnError bool
+	// Dependencies are the IDs of the jobs that each job depends on. If it's set, the jobs run in
+	// dependency order (a job only starts when its dependencies succeeded), and a cycle is an error.
+	Dependencies map[string][]string
+	// Reverse runs the jobs in reverse dependency order (e.g. destroy): a job only starts when the jobs
+	// that depend on it succeeded.
+	Reverse bool
+}
+
+type JobResult struct {
+	ID       string
+	Output   string
+	Err      error
+	Skipped  bool
+	Duration time.Duration
+}
+
+// JobResults are the results of the jobs, keyed by job ID.
+type JobResults map[string]*JobResult
+
+// GetFailed returns the IDs of the jobs that failed, sorted.
+func (r JobResults) GetFailed() []string {
+	var failed []string
+	for id, result := range r {
+		if result.Err != nil {
+			failed = append(failed, id)
+		}
+	}
+
+	sort.Strings(failed)
+	return failed
+}
+
+// GetSkipped returns the IDs of the jobs that weren't run, sorted.
+func (r JobResults) GetSkipped() []string {
+	var skipped []string
+	for id, result := range r {
+		if result.Skipped {
+			skipped = append(skipped, id)
+		}
+	}
+
+	sort.Strings(skipped)
+	return skipped
+}
+
+func (o *OrchestratorOptions) getConcurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+
+	return o.Concurrency
+}
+
+// withContext returns a TD that shares the engine, config and logger, but uses the given context. The
+// functions registered with OnClose run when the parent TD is closed, since it owns the engine. It has
+// its own interrupt marker, so cancelling the jobs doesn't interrupt the next commands of the parent.
+func (td *TD) withContext(ctx context.Context, cancel context.CancelFunc) *TD {
+	return &TD{
+		Ctx:                  ctx,
+		Log:                  td.Log,
+		Engine:               td.Engine,
+		Config:               td.Config,
+		ID:                   td.ID,
+		cancel:               cancel,
+		parent:               td,
+		interruptID:          utils.GetUUID(),
+		interruptGracePeriod: td.interruptGracePeriod,
+	}
+}
+
+// RunJobs runs the jobs concurrently on the same Dagger client, with at most Concurrency jobs at the
+// same time, and in dependency order if Dependencies is set. It returns the result of every job
+// (keyed by ID), and an ErrTerraDaggerJobsFailedError if any of them failed.
+func (td *TD) RunJobs(jobs []Job, options OrchestratorOptions) (JobResults, error) {
+	results := make(JobResults, len(jobs))
+	for _, job := range jobs {
+		if job.ID == "" {
+			return nil, erroer.NewErrTerraDaggerInvalidArgumentError("the job ID cannot be empty", nil)
+		}
+
+		if job.Run == nil {
+			return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the job %s has nothing to run", job.ID), nil)
+		}
+
+		if _, ok := results[job.ID]; ok {
+			return nil, erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the job %s is duplicated", job.ID), nil)
+		}
+
+		results[job.ID] = &JobResult{ID: job.ID, Skipped: true}
+	}
+
+	levels, dag, err := getJobLevels(jobs, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(td.Ctx)
+	defer cancel()
+
+	jobsTD := td.withContext(ctx, cancel)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	sem := make(chan struct{}, options.getConcurrency())
+
+	for _, level := range levels {
+		for _, job := range level {
+			// The workers of this level write their results while the next jobs are scheduled.
+			mu.Lock()
+			succeeded := dag == nil || areJobsSucceeded(results, getRequiredJobs(dag, job.ID, options.Reverse))
+			mu.Unlock()
+
+			if !succeeded {
+				td.Log.Warn(fmt.Sprintf("Skipping job %s, since the jobs it depends on didn't succeed", job.ID))
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(job Job) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				start := time.Now()
+				td.Log.Info(fmt.Sprintf("Running job %s", job.ID))
+
+				out, err := job.Run(jobsTD)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				results[job.ID] = &JobResult{
+					ID:       job.ID,
+					Output:   out,
+					Err:      err,
+					Duration: time.Since(start),
+				}
+
+				if err == nil {
+					return
+				}
+
+				td.Log.Warn(fmt.Sprintf("Job %s failed: %s", job.ID, err))
+
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				if !options.ContinueOnError {
+					cancel()
+				}
+			}(job)
+		}
+
+		// The next level depends on the results of this one.
+		wg.Wait()
+	}
+
+	if failed := results.GetFailed(); len(failed) > 0 {
+		return results, erroer.NewErrTerraDaggerJobsFailedError(failed, firstErr)
+	}
+
+	if err := td.Ctx.Err(); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// getJobLevels groups the jobs in levels that run one after the other. Without dependencies, all the
+// jobs are in the same level.
+func getJobLevels(jobs []Job, options OrchestratorOptions) ([][]Job, *DAG, error) {
+	if options.Dependencies == nil {
+		return [][]Job{jobs}, nil, nil
+	}
+
+	byID := map[string]Job{}
+	dependencies := map[string][]string{}
+	for _, job := range jobs {
+		byID[job.ID] = job
+		dependencies[job.ID] = options.Dependencies[job.ID]
+	}
+
+	dag, err := NewDAG(dependencies)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idLevels, err := dag.Levels()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.Reverse {
+		idLevels = Reverse(idLevels)
+	}
+
+	levels := make([][]Job, 0, len(idLevels))
+	for _, ids := range idLevels {
+		level := make([]Job, 0, len(ids))
+		for _, id := range ids {
+			level = append(level, byID[id])
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels, dag, nil
+}
+
+// getRequiredJobs returns the jobs that must succeed before the given one starts.
+func getRequiredJobs(dag *DAG, id string, reverse bool) []string {
+	if reverse {
+		return dag.GetDependents(id)
+	}
+
+	return dag.GetDependencies(id)
+}
+
+func areJobsSucceeded(results JobResults, ids []string) bool {
+	for _, id := range ids {
+		if result := results[id]; result == nil || result.Skipped || result.Err != nil {
+			return false
+		}
+	}
+
+	return true
+}
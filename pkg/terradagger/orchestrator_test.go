@@ -0,0 +1,160 @@
+package terradagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunJobs_ConcurrencyLimit(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	var running, maxRunning int32
+	var jobs []Job
+	for i := 0; i < 10; i++ {
+		jobs = append(jobs, Job{
+			ID: fmt.Sprintf("module-%d", i),
+			Run: func(td *TD) (string, error) {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return "ok", nil
+			},
+		})
+	}
+
+	results, err := td.RunJobs(jobs, OrchestratorOptions{Concurrency: 3})
+	assert.NoError(t, err)
+	assert.Len(t, results, 10)
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Equal(t, "ok", results["module-0"].Output)
+}
+
+func TestRunJobs_FailFast(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	jobs := []Job{
+		{ID: "failing", Run: func(td *TD) (string, error) { return "", errors.New("boom") }},
+		{ID: "cancelled", Run: func(td *TD) (string, error) {
+			<-td.Ctx.Done()
+			return "", td.Ctx.Err()
+		}},
+		{ID: "skipped", Run: func(td *TD) (string, error) { return "ok", nil }},
+	}
+
+	results, err := td.RunJobs(jobs, OrchestratorOptions{Concurrency: 2})
+
+	var jobsErr *erroer.ErrTerraDaggerJobsFailedError
+	assert.ErrorAs(t, err, &jobsErr)
+	assert.Equal(t, []string{"cancelled", "failing"}, jobsErr.Failed)
+	assert.Equal(t, []string{"skipped"}, results.GetSkipped())
+	assert.NoError(t, td.Ctx.Err())
+}
+
+func TestRunJobs_ContinueOnError(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	jobs := []Job{
+		{ID: "failing", Run: func(td *TD) (string, error) { return "", errors.New("boom") }},
+		{ID: "a", Run: func(td *TD) (string, error) { return "ok", nil }},
+		{ID: "b", Run: func(td *TD) (string, error) { return "ok", nil }},
+	}
+
+	results, err := td.RunJobs(jobs, OrchestratorOptions{Concurrency: 1, ContinueOnError: true})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"failing"}, results.GetFailed())
+	assert.Empty(t, results.GetSkipped())
+	assert.Equal(t, "ok", results["b"].Output)
+}
+
+func TestRunJobs_DuplicatedID(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	run := func(td *TD) (string, error) { return "", nil }
+	_, err := td.RunJobs([]Job{{ID: "a", Run: run}, {ID: "a", Run: run}}, OrchestratorOptions{})
+	assert.Error(t, err)
+}
+
+func TestRunJobs_Dependencies(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	var mu sync.Mutex
+	var order []string
+	run := func(id string, err error) func(td *TD) (string, error) {
+		return func(td *TD) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, id)
+			return id, err
+		}
+	}
+
+	dependencies := map[string][]string{
+		"app":      {"database"},
+		"database": {"network"},
+	}
+
+	jobs := []Job{
+		{ID: "app", Run: run("app", nil)},
+		{ID: "database", Run: run("database", nil)},
+		{ID: "network", Run: run("network", nil)},
+	}
+
+	_, err := td.RunJobs(jobs, OrchestratorOptions{Dependencies: dependencies})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"network", "database", "app"}, order)
+
+	order = nil
+	_, err = td.RunJobs(jobs, OrchestratorOptions{Dependencies: dependencies, Reverse: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "database", "network"}, order)
+
+	order = nil
+	jobs[1].Run = run("database", errors.New("boom"))
+	results, err := td.RunJobs(jobs, OrchestratorOptions{Dependencies: dependencies, ContinueOnError: true})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"network", "database"}, order)
+	assert.Equal(t, []string{"app"}, results.GetSkipped())
+}
+
+// TestRunJobs_DependenciesConcurrent runs several dependent jobs per level, so the results are written
+// by the workers while the next jobs are scheduled. Run it with -race.
+func TestRunJobs_DependenciesConcurrent(t *testing.T) {
+	td := New(context.Background(), &Options{})
+
+	dependencies := map[string][]string{}
+	var jobs []Job
+	for level := 0; level < 3; level++ {
+		for i := 0; i < 4; i++ {
+			id := fmt.Sprintf("level-%d-%d", level, i)
+			if level > 0 {
+				dependencies[id] = []string{fmt.Sprintf("level-%d-%d", level-1, i), fmt.Sprintf("level-%d-%d", level-1, (i+1)%4)}
+			} else {
+				dependencies[id] = nil
+			}
+
+			jobs = append(jobs, Job{ID: id, Run: func(td *TD) (string, error) {
+				time.Sleep(time.Millisecond)
+				return "ok", nil
+			}})
+		}
+	}
+
+	results, err := td.RunJobs(jobs, OrchestratorOptions{Concurrency: 2, Dependencies: dependencies})
+	assert.NoError(t, err)
+	assert.Empty(t, results.GetSkipped())
+	assert.Len(t, results, 12)
+}
@@ -0,0 +1,73 @@
+package terradaggertest
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultRetryableErrors are the transient errors that are commonly worth retrying, e.g. when the
+// providers are downloaded, or when the cloud APIs are eventually consistent.
+var DefaultRetryableErrors = map[string]string{
+	".*read: connection reset by peer.*":              "Failed to reach the registry, due to a transient network error.",
+	".*TLS handshake timeout.*":                       "Failed to reach the registry, due to a transient network error.",
+	".*Error installing provider.*":                   "Failed to install the provider, due to a transient network error.",
+	".*Failed to query available provider packages.*": "Failed to query the provider registry, due to a transient network error.",
+	".*timeout while waiting for state to become.*":   "The resource didn't reach the expected state in time.",
+}
+
+// DoWithRetry runs the function until it succeeds, or it fails with an error that isn't retryable,
+// or the retries are exhausted. The test fails if the function doesn't succeed.
+func DoWithRetry(t testing.TB, description string, maxRetries int, timeBetweenRetries time.Duration,
+	retryableErrors map[string]string, fn func() (string, error)) string {
+	t.Helper()
+
+	out, err := DoWithRetryE(t, description, maxRetries, timeBetweenRetries, retryableErrors, fn)
+	require.NoError(t, err)
+
+	return out
+}
+
+// DoWithRetryE runs the function until it succeeds, or it fails with an error that isn't retryable
+// (it matches none of the retryable errors), or the retries are exhausted.
+func DoWithRetryE(t testing.TB, description string, maxRetries int, timeBetweenRetries time.Duration,
+	retryableErrors map[string]string, fn func() (string, error)) (string, error) {
+	t.Helper()
+
+	var (
+		out string
+		err error
+	)
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		out, err = fn()
+		if err == nil {
+			return out, nil
+		}
+
+		reason, retryable := getRetryableError(err, retryableErrors)
+		if !retryable || attempt == maxRetries {
+			break
+		}
+
+		t.Logf("%s failed (%s), retrying in %s (%d/%d)", description, reason, timeBetweenRetries, attempt+1, maxRetries)
+		time.Sleep(timeBetweenRetries)
+	}
+
+	return out, fmt.Errorf("%s failed: %w", description, err)
+}
+
+// getRetryableError returns the description of the first retryable error that matches the error.
+func getRetryableError(err error, retryableErrors map[string]string) (string, bool) {
+	for pattern, description := range retryableErrors {
+		matched, matchErr := regexp.MatchString(pattern, err.Error())
+		if matchErr == nil && matched {
+			return description, true
+		}
+	}
+
+	return "", false
+}
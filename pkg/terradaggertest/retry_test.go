@@ -0,0 +1,41 @@
+package terradaggertest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoWithRetryE(t *testing.T) {
+	retryable := map[string]string{".*connection reset.*": "transient network error"}
+
+	attempts := 0
+	out, err := DoWithRetryE(t, "flaky", 3, 0, retryable, func() (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", errors.New("read: connection reset by peer")
+		}
+
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", out)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	_, err = DoWithRetryE(t, "broken", 3, 0, retryable, func() (string, error) {
+		attempts++
+		return "", errors.New("invalid configuration")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	_, err = DoWithRetryE(t, "always flaky", 2, 0, retryable, func() (string, error) {
+		attempts++
+		return "", errors.New("connection reset")
+	})
+	assert.ErrorContains(t, err, "always flaky failed")
+	assert.Equal(t, 3, attempts)
+}
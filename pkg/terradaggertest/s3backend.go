@@ -0,0 +1,202 @@
+package terradaggertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// defaultS3BackendImage is a pinned release of MinIO. Its image also has the mc client, which
+	// creates the bucket.
+	defaultS3BackendImage     = "minio/minio:RELEASE.2024-01-16T16-07-38Z"
+	defaultS3BackendAlias     = "s3"
+	defaultS3BackendBucket    = "terraform-state"
+	defaultS3BackendRegion    = "us-east-1"
+	defaultS3BackendAccessKey = "terradagger"
+	defaultS3BackendSecretKey = "terradagger"
+	s3BackendPort             = 9000
+)
+
+type S3BackendOptions struct {
+	// Alias is the hostname of the S3-compatible server, as seen from the terraform container. Defaults to s3.
+	Alias string
+	// Bucket is the bucket where the states are stored. It's created when the backend starts.
+	Bucket string
+	// Region is the region passed to the backend. Defaults to us-east-1.
+	Region string
+	// AccessKey and SecretKey are the credentials of the S3-compatible server.
+	AccessKey string
+	SecretKey string
+	// Image is the image of the S3-compatible server, e.g. a mirror of minio/minio. Defaults to a pinned
+	// release of minio/minio.
+	Image string
+	// ClientImage is the image with the mc client, used to create the bucket. Defaults to the Image.
+	ClientImage string
+	// PinImageDigests pins the images by digest, with the lock file of the workspace (see
+	// container.PinImage), so every run uses the same images, also offline once they're cached.
+	PinImageDigests bool
+	// StrictImageDigests fails if an image doesn't match the digest of the lock file.
+	StrictImageDigests bool
+	// LegacyEndpoint uses the endpoint and force_path_style arguments, for terraform versions older than 1.6.
+	LegacyEndpoint bool
+}
+
+// S3Backend is a local S3-compatible server (MinIO), running as a Dagger service, that's used as the
+// S3 backend of the module under test, so it can be initialized and applied offline.
+type S3Backend struct {
+	options S3BackendOptions
+	service *dagger.Service
+}
+
+func (o *S3BackendOptions) setDefaults() {
+	if o.Alias == "" {
+		o.Alias = defaultS3BackendAlias
+	}
+
+	if o.Bucket == "" {
+		o.Bucket = defaultS3BackendBucket
+	}
+
+	if o.Region == "" {
+		o.Region = defaultS3BackendRegion
+	}
+
+	if o.AccessKey == "" {
+		o.AccessKey = defaultS3BackendAccessKey
+	}
+
+	if o.SecretKey == "" {
+		o.SecretKey = defaultS3BackendSecretKey
+	}
+
+	if o.Image == "" {
+		o.Image = defaultS3BackendImage
+	}
+
+	if o.ClientImage == "" {
+		o.ClientImage = o.Image
+	}
+}
+
+// NewS3Backend starts the S3-compatible server, and fails the test if it isn't ready.
+func NewS3Backend(t testing.TB, td *terradagger.TD, options S3BackendOptions) *S3Backend {
+	t.Helper()
+
+	backend, err := NewS3BackendE(td, options)
+	require.NoError(t, err)
+
+	return backend
+}
+
+// NewS3BackendE starts the S3-compatible server, waits until it's ready, and creates the bucket.
+func NewS3BackendE(td *terradagger.TD, options S3BackendOptions) (*S3Backend, error) {
+	if td == nil || td.Engine == nil || td.Engine.GetEngine() == nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError("the dagger engine must be started before the S3 backend", nil)
+	}
+
+	options.setDefaults()
+	client := td.Engine.GetEngine()
+
+	if options.PinImageDigests || options.StrictImageDigests {
+		lockFile := filepath.Join(td.Config.GetWorkspaceAbs(), td.Config.GetTerraDaggerDir(), container.ImageLockFile)
+		for _, image := range []*string{&options.Image, &options.ClientImage} {
+			pinnedImage, err := container.PinImage(td, *image, "", lockFile, options.StrictImageDigests, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			*image = pinnedImage
+		}
+	}
+
+	service := client.Container().
+		From(options.Image).
+		WithEnvVariable("MINIO_ROOT_USER", options.AccessKey).
+		WithEnvVariable("MINIO_ROOT_PASSWORD", options.SecretKey).
+		WithExposedPort(s3BackendPort).
+		WithExec([]string{"server", "/data"}).
+		AsService()
+
+	// The bucket is created once the server accepts connections, which also makes the backend ready
+	// for the terraform containers that are bound to it.
+	createBucketCmd := fmt.Sprintf("for i in $(seq 1 30); do mc alias set local %s %s %s >/dev/null 2>&1 && break; sleep 1; done; mc mb --ignore-existing local/%s",
+		getS3BackendEndpoint(options.Alias), options.AccessKey, options.SecretKey, options.Bucket)
+
+	_, err := client.Container().
+		From(options.ClientImage).
+		WithServiceBinding(options.Alias, service).
+		WithoutEntrypoint().
+		WithExec([]string{"sh", "-c", createBucketCmd}).
+		Sync(td.Ctx)
+	if err != nil {
+		return nil, erroer.NewErrTerraDaggerServiceNotReadyError(options.Alias, fmt.Sprintf("unable to create the bucket %s", options.Bucket), err)
+	}
+
+	td.Log.Info(fmt.Sprintf("S3 backend started at %s, with the bucket %s", getS3BackendEndpoint(options.Alias), options.Bucket))
+
+	return &S3Backend{options: options, service: service}, nil
+}
+
+func getS3BackendEndpoint(alias string) string {
+	return fmt.Sprintf("http://%s:%d", alias, s3BackendPort)
+}
+
+// GetServiceBinding returns the binding that makes the S3-compatible server reachable from the terraform container.
+func (b *S3Backend) GetServiceBinding() container.ServiceBinding {
+	return container.ServiceBinding{Alias: b.options.Alias, Service: b.service}
+}
+
+// GetBackendConfig returns the backend configuration (for -backend-config) that points the S3
+// backend to the local server, for the state stored in the given key.
+func (b *S3Backend) GetBackendConfig(key string) map[string]string {
+	return getS3BackendConfig(b.options, key)
+}
+
+func getS3BackendConfig(options S3BackendOptions, key string) map[string]string {
+	backendConfig := map[string]string{
+		"bucket":                      options.Bucket,
+		"key":                         key,
+		"region":                      options.Region,
+		"access_key":                  options.AccessKey,
+		"secret_key":                  options.SecretKey,
+		"skip_credentials_validation": "true",
+		"skip_region_validation":      "true",
+		"skip_metadata_api_check":     "true",
+	}
+
+	endpoint := getS3BackendEndpoint(options.Alias)
+	if options.LegacyEndpoint {
+		backendConfig["endpoint"] = endpoint
+		backendConfig["force_path_style"] = "true"
+
+		return backendConfig
+	}
+
+	backendConfig["endpoints"] = fmt.Sprintf(`{s3="%s"}`, endpoint)
+	backendConfig["use_path_style"] = "true"
+	backendConfig["skip_requesting_account_id"] = "true"
+	backendConfig["skip_s3_checksum"] = "true"
+
+	return backendConfig
+}
+
+// Configure binds the S3-compatible server to the terraform container of the options, and adds the
+// backend configuration for the state stored in the given key.
+func (b *S3Backend) Configure(tfOptions *terraformcore.TfOptions, key string) {
+	tfOptions.ServiceBindings = append(tfOptions.ServiceBindings, b.GetServiceBinding())
+
+	backendConfig := b.GetBackendConfig(key)
+	for k, v := range tfOptions.BackendConfig {
+		backendConfig[k] = v
+	}
+
+	tfOptions.BackendConfig = backendConfig
+}
@@ -0,0 +1,58 @@
+package terradaggertest
+
+import (
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetS3BackendConfig(t *testing.T) {
+	options := S3BackendOptions{}
+	options.setDefaults()
+
+	backendConfig := getS3BackendConfig(options, "network/terraform.tfstate")
+	assert.Equal(t, "terraform-state", backendConfig["bucket"])
+	assert.Equal(t, "network/terraform.tfstate", backendConfig["key"])
+	assert.Equal(t, `{s3="http://s3:9000"}`, backendConfig["endpoints"])
+	assert.Equal(t, "true", backendConfig["use_path_style"])
+	assert.Equal(t, "true", backendConfig["skip_credentials_validation"])
+	assert.NotContains(t, backendConfig, "endpoint")
+
+	options.Alias = "minio"
+	options.LegacyEndpoint = true
+
+	legacy := getS3BackendConfig(options, "app.tfstate")
+	assert.Equal(t, "http://minio:9000", legacy["endpoint"])
+	assert.Equal(t, "true", legacy["force_path_style"])
+	assert.NotContains(t, legacy, "endpoints")
+}
+
+func TestS3Backend_Configure(t *testing.T) {
+	options := S3BackendOptions{}
+	options.setDefaults()
+
+	backend := &S3Backend{options: options}
+	tfOptions := &terraformcore.TfOptions{
+		BackendConfig: map[string]string{"region": "eu-west-1"},
+	}
+
+	backend.Configure(tfOptions, "app.tfstate")
+	assert.Len(t, tfOptions.ServiceBindings, 1)
+	assert.Equal(t, "s3", tfOptions.ServiceBindings[0].Alias)
+	assert.Equal(t, "eu-west-1", tfOptions.BackendConfig["region"])
+	assert.Equal(t, "app.tfstate", tfOptions.BackendConfig["key"])
+}
+
+func TestS3BackendOptions_setDefaults_Images(t *testing.T) {
+	options := S3BackendOptions{}
+	options.setDefaults()
+
+	assert.Equal(t, defaultS3BackendImage, options.Image)
+	assert.NotContains(t, options.Image, ":latest")
+	assert.Equal(t, options.Image, options.ClientImage)
+
+	mirrored := S3BackendOptions{Image: "registry.example.com/minio/minio:RELEASE.2024-01-16T16-07-38Z"}
+	mirrored.setDefaults()
+	assert.Equal(t, mirrored.Image, mirrored.ClientImage)
+}
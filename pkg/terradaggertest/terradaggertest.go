@@ -0,0 +1,363 @@
+// Package terradaggertest provides Terratest-style helpers to test terraform modules, running every
+// command in containers through terradagger.
+package terradaggertest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/config"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraform"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+	"github.com/stretchr/testify/require"
+)
+
+type Options struct {
+	// TD is the terradagger client. If it's nil, a new one is created for the workspace, and it's
+	// closed when the test finishes.
+	TD *terradagger.TD
+	// Workspace is the workspace of the terradagger client created when TD is nil.
+	Workspace string
+	// TfOptions are the options of the module under test (module path, terraform version, etc.)
+	TfOptions *terraformcore.TfOptions
+	// TerraformVarFiles is a list of terraform var files to use
+	TerraformVarFiles []string
+	// Vars is a list of terraform vars to use
+	Vars []terraformcore.TFInputVariable
+	// NoDestroy skips the destroy that's registered as a test cleanup by InitAndApply.
+	NoDestroy bool
+	// MaxRetries is the number of times a command is retried, if it fails with a retryable error.
+	MaxRetries int
+	// TimeBetweenRetries is the time to wait between retries.
+	TimeBetweenRetries time.Duration
+	// RetryableErrors are the errors that are retried: a regular expression that matches the error,
+	// and its description.
+	RetryableErrors map[string]string
+}
+
+// Module is a module that was applied (or planned) in a container. The next commands (output,
+// plan, destroy) run on the same container, so they see the state created by the apply.
+type Module struct {
+	// Stdout is the output of the last command that ran on the module.
+	Stdout string
+
+	td        *terradagger.TD
+	options   *Options
+	runtime   container.Runtime
+	container *dagger.Container
+	destroyed bool
+}
+
+func getTD(t testing.TB, options *Options) *terradagger.TD {
+	t.Helper()
+
+	if options.TD != nil {
+		return options.TD
+	}
+
+	td := terradagger.New(context.Background(), &terradagger.Options{
+		Workspace: options.Workspace,
+	})
+
+	require.NoError(t, td.StartEngine(), "unable to start the Dagger engine")
+	t.Cleanup(func() {
+		_ = td.Close()
+	})
+
+	options.TD = td
+	return td
+}
+
+func getTfOptions(options *Options) (*terraformcore.TfOptions, error) {
+	if options == nil || options.TfOptions == nil {
+		return nil, erroer.NewErrTerraDaggerInvalidArgumentError("the terraform options of the module under test are required", nil)
+	}
+
+	return options.TfOptions, nil
+}
+
+// InitAndApply runs terraform init and apply, and fails the test if any of them fails. Unless
+// NoDestroy is set, the module is destroyed when the test finishes.
+func InitAndApply(t testing.TB, options *Options) *Module {
+	t.Helper()
+
+	module, err := InitAndApplyE(t, options)
+	require.NoError(t, err)
+
+	return module
+}
+
+// InitAndApplyE runs terraform init and apply, and returns the applied module. Unless NoDestroy is set,
+// the module is destroyed when the test finishes, even if the apply fails.
+func InitAndApplyE(t testing.TB, options *Options) (*Module, error) {
+	t.Helper()
+
+	tfOptions, err := getTfOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	td := getTD(t, options)
+	module := &Module{td: td, options: options}
+
+	// The destroy is registered before the apply, so a failed (or partial) apply doesn't leak the
+	// resources it created.
+	if !options.NoDestroy {
+		t.Cleanup(func() {
+			if _, err := module.DestroyE(); err != nil {
+				t.Errorf("unable to destroy %s: %s", tfOptions.ModulePath, err)
+			}
+		})
+	}
+
+	_, err = DoWithRetryE(t, fmt.Sprintf("apply %s", tfOptions.ModulePath), options.MaxRetries, options.TimeBetweenRetries, options.RetryableErrors,
+		func() (string, error) {
+			applyContainer, runtime, err := terraform.Apply(td, terraformcore.WithOptions(td, tfOptions), terraform.ApplyOptions{
+				TerraformVarFiles: options.TerraformVarFiles,
+				Vars:              options.Vars,
+				AutoApprove:       true,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			out, err := runtime.RunAndGetStdout(applyContainer)
+			if err != nil {
+				return "", err
+			}
+
+			module.runtime = runtime
+			module.container = applyContainer
+			module.Stdout = out
+
+			return out, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return module, nil
+}
+
+// InitAndPlan runs terraform init and plan, and returns the plan output.
+func InitAndPlan(t testing.TB, options *Options) string {
+	t.Helper()
+
+	out, err := InitAndPlanE(t, options)
+	require.NoError(t, err)
+
+	return out
+}
+
+// InitAndPlanE runs terraform init and plan, and returns the plan output.
+func InitAndPlanE(t testing.TB, options *Options) (string, error) {
+	t.Helper()
+
+	tfOptions, err := getTfOptions(options)
+	if err != nil {
+		return "", err
+	}
+
+	td := getTD(t, options)
+
+	return DoWithRetryE(t, fmt.Sprintf("plan %s", tfOptions.ModulePath), options.MaxRetries, options.TimeBetweenRetries, options.RetryableErrors,
+		func() (string, error) {
+			return terraform.PlanE(td, terraformcore.WithOptions(td, tfOptions), terraform.PlanOptions{
+				TerraformVarFiles: options.TerraformVarFiles,
+				Vars:              options.Vars,
+			})
+		})
+}
+
+// InitAndApplyAndIdempotent runs terraform init and apply, and fails the test if a plan right after
+// the apply still shows changes.
+func InitAndApplyAndIdempotent(t testing.TB, options *Options) *Module {
+	t.Helper()
+
+	module := InitAndApply(t, options)
+	module.AssertIdempotent(t)
+
+	return module
+}
+
+// run runs the terraform command on the container of the module.
+func (m *Module) run(command string, args []string, interruptible bool) (*dagger.Container, string, error) {
+	cmd := terradagger.BuildTerraformCommand(terradagger.BuildTerraformCommandOptions{
+		Binary:      config.IacToolTerraform,
+		Command:     command,
+		CommandArgs: args,
+	})
+
+	shellCmd := terradagger.BuildCMDWithSH(cmd)
+	if interruptible {
+		shellCmd = terradagger.BuildInterruptibleCMDWithSH(cmd, m.td.GetInterruptAt(), m.td.GetInterruptMarkerFile())
+	}
+
+	next := m.runtime.AddCommands([]container.Command{shellCmd}, m.container)
+	out, err := m.runtime.RunAndGetStdout(next)
+
+	return next, out, err
+}
+
+// OutputsE returns all the outputs of the module (terraform output -json).
+func (m *Module) OutputsE() (map[string]terraformcore.TfOutput, error) {
+	_, out, err := m.run("output", []string{"-json"}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return terraformcore.ParseOutputsJSON(out)
+}
+
+// Outputs returns all the outputs of the module.
+func (m *Module) Outputs(t testing.TB) map[string]terraformcore.TfOutput {
+	t.Helper()
+
+	outputs, err := m.OutputsE()
+	require.NoError(t, err)
+
+	return outputs
+}
+
+// OutputE returns the value of the output: strings are unquoted, and the other types are returned as JSON.
+func (m *Module) OutputE(name string) (string, error) {
+	outputs, err := m.OutputsE()
+	if err != nil {
+		return "", err
+	}
+
+	output, ok := outputs[name]
+	if !ok {
+		return "", erroer.NewErrTerraDaggerInvalidArgumentError(fmt.Sprintf("the output %s doesn't exist", name), nil)
+	}
+
+	return output.GetValueString(), nil
+}
+
+// Output returns the value of the output, and fails the test if it doesn't exist.
+func (m *Module) Output(t testing.TB, name string) string {
+	t.Helper()
+
+	value, err := m.OutputE(name)
+	require.NoError(t, err)
+
+	return value
+}
+
+// OutputJSON decodes the value of the output (e.g. a map or a list) into v.
+func (m *Module) OutputJSON(t testing.TB, name string, v any) {
+	t.Helper()
+
+	outputs := m.Outputs(t)
+
+	output, ok := outputs[name]
+	require.True(t, ok, "the output %s doesn't exist", name)
+	require.NoError(t, output.DecodeValue(v))
+}
+
+// AssertOutputEquals checks that the output has the expected value.
+func (m *Module) AssertOutputEquals(t testing.TB, name, expected string) {
+	t.Helper()
+
+	require.Equal(t, expected, m.Output(t, name), "unexpected value of the output %s", name)
+}
+
+// AssertOutputNotEmpty checks that the output exists, and it isn't empty.
+func (m *Module) AssertOutputNotEmpty(t testing.TB, name string) {
+	t.Helper()
+
+	require.NotEmpty(t, m.Output(t, name), "the output %s is empty", name)
+}
+
+// VerifyIdempotentE runs plan -detailed-exitcode on the module, and returns an
+// erroer.ErrTerraformCoreNotIdempotentError listing the resources that still show changes.
+func (m *Module) VerifyIdempotentE() error {
+	iac := &terraformcore.IasC{
+		Config: &terraformcore.IacConfigOptions{Binary: config.IacToolTerraform},
+	}
+
+	return iac.VerifyIdempotent(m.td, m.runtime, m.container, m.getArgs())
+}
+
+// AssertIdempotent fails the test if a plan right after the apply shows changes.
+func (m *Module) AssertIdempotent(t testing.TB) {
+	t.Helper()
+
+	require.NoError(t, m.VerifyIdempotentE(), "the module isn't idempotent")
+}
+
+// DestroyE destroys the module, on the same container where it was applied. It's a no-op if the
+// module was already destroyed.
+func (m *Module) DestroyE() (string, error) {
+	if m.destroyed {
+		return "", nil
+	}
+
+	if m.container == nil {
+		return m.destroyAfterFailedApply()
+	}
+
+	args := []string{"-auto-approve", "-input=false"}
+	args = append(args, m.getArgs()...)
+
+	destroyContainer, out, err := m.run("destroy", args, true)
+	if err != nil {
+		return "", err
+	}
+
+	m.container = destroyContainer
+	m.destroyed = true
+	m.Stdout = out
+
+	return out, nil
+}
+
+// destroyAfterFailedApply destroys the module when its apply failed, so there's no container to run
+// the destroy on: it runs init and destroy on a new container. The state is read from the backend of
+// the module; a local state is lost with the container of the failed apply.
+func (m *Module) destroyAfterFailedApply() (string, error) {
+	tfOptions, err := getTfOptions(m.options)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := terraform.DestroyE(m.td, terraformcore.WithOptions(m.td, tfOptions), terraform.DestroyOptions{
+		TerraformVarFiles: m.options.TerraformVarFiles,
+		Vars:              m.options.Vars,
+		AutoApprove:       true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	m.destroyed = true
+	m.Stdout = out
+
+	return out, nil
+}
+
+// Destroy destroys the module, and fails the test if it can't.
+func (m *Module) Destroy(t testing.TB) string {
+	t.Helper()
+
+	out, err := m.DestroyE()
+	require.NoError(t, err)
+
+	return out
+}
+
+// getArgs returns the -var and -var-file arguments of the module.
+func (m *Module) getArgs() []string {
+	args := &terraformcore.DestroyArgsOptions{
+		TerraformVarFiles: m.options.TerraformVarFiles,
+		Vars:              m.options.Vars,
+	}
+
+	return append(args.GetArgVars(), args.GetArgTerraformVarFiles()...)
+}
@@ -16,8 +16,12 @@ type DestroyOptions struct {
 	TerraformVarFiles []string
 	// Vars is a list of terraform vars to use
 	Vars []terraformcore.TFInputVariable
+	// JSON is a flag to output the machine-readable UI, equivalent to terraform destroy -json
+	JSON bool
 	// AutoApprove is a flag to auto approve the plan
 	AutoApprove bool
+	// Guardrails, if set, inspects the plan and refuses destructive changes before running the command
+	Guardrails *terraformcore.Guardrails
 }
 
 func Destroy(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options DestroyOptions) (*dagger.Container, container.Runtime, error) {
@@ -28,6 +32,8 @@ func Destroy(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options D
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
 		AutoApprove:       options.AutoApprove,
+		Guardrails:        options.Guardrails,
+		JSON:              options.JSON,
 		TfGlobalOptions:   tfOpts,
 	})
 }
@@ -40,6 +46,8 @@ func DestroyE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
 		AutoApprove:       options.AutoApprove,
+		Guardrails:        options.Guardrails,
+		JSON:              options.JSON,
 		TfGlobalOptions:   tfOpts,
 	})
 }
@@ -0,0 +1,48 @@
+package terraform
+
+import (
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+)
+
+// ManyOptions configures how many modules are run at the same time, and what happens when one fails.
+type ManyOptions = terradagger.OrchestratorOptions
+
+// RunManyE runs the function for each module concurrently, on the same Dagger client. The results
+// are keyed by module path.
+func RunManyE(td *terradagger.TD, modules []*terraformcore.TfOptions, options ManyOptions,
+	run func(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (string, error)) (terradagger.JobResults, error) {
+	jobs := make([]terradagger.Job, 0, len(modules))
+	for _, module := range modules {
+		module := module
+		jobs = append(jobs, terradagger.Job{
+			ID: module.ModulePath,
+			Run: func(jobTD *terradagger.TD) (string, error) {
+				return run(jobTD, terraformcore.WithOptions(jobTD, module))
+			},
+		})
+	}
+
+	return td.RunJobs(jobs, options)
+}
+
+// PlanManyE runs terraform plan on each module concurrently.
+func PlanManyE(td *terradagger.TD, modules []*terraformcore.TfOptions, planOptions PlanOptions, options ManyOptions) (terradagger.JobResults, error) {
+	return RunManyE(td, modules, options, func(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (string, error) {
+		return PlanE(td, tfOpts, planOptions)
+	})
+}
+
+// ApplyManyE runs terraform apply on each module concurrently.
+func ApplyManyE(td *terradagger.TD, modules []*terraformcore.TfOptions, applyOptions ApplyOptions, options ManyOptions) (terradagger.JobResults, error) {
+	return RunManyE(td, modules, options, func(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (string, error) {
+		return ApplyE(td, tfOpts, applyOptions)
+	})
+}
+
+// DestroyManyE runs terraform destroy on each module concurrently.
+func DestroyManyE(td *terradagger.TD, modules []*terraformcore.TfOptions, destroyOptions DestroyOptions, options ManyOptions) (terradagger.JobResults, error) {
+	return RunManyE(td, modules, options, func(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (string, error) {
+		return DestroyE(td, tfOpts, destroyOptions)
+	})
+}
@@ -0,0 +1,77 @@
+package terraform
+
+import (
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/config"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+)
+
+type ProvidersMirrorOptions struct {
+	// TargetDir is the directory of the host where the providers are saved. If it's relative, it's
+	// relative to the terradagger workspace
+	TargetDir string
+	// Platforms are the platforms of the providers to download, e.g. linux_amd64
+	Platforms []string
+}
+
+func ProvidersMirror(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ProvidersMirrorOptions) (*dagger.Container, container.Runtime, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersMirror(config.IacToolTerraform, &terraformcore.ProvidersMirrorArgsOptions{
+		TargetDir:       options.TargetDir,
+		Platforms:       options.Platforms,
+		TfGlobalOptions: tfOpts,
+	})
+}
+
+// ProvidersMirrorE downloads the providers required by the module into the target directory of the
+// host, which can be used as a filesystem mirror (terraformcore.ProviderMirror) in air-gapped runs.
+func ProvidersMirrorE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ProvidersMirrorOptions) (string, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersMirrorE(config.IacToolTerraform, &terraformcore.ProvidersMirrorArgsOptions{
+		TargetDir:       options.TargetDir,
+		Platforms:       options.Platforms,
+		TfGlobalOptions: tfOpts,
+	})
+}
+
+type ProvidersLockOptions struct {
+	// Platforms are the platforms whose checksums are recorded in the lock file, e.g. linux_amd64
+	Platforms []string
+}
+
+func ProvidersLock(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ProvidersLockOptions) (*dagger.Container, container.Runtime, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersLock(config.IacToolTerraform, &terraformcore.ProvidersLockArgsOptions{
+		Platforms:       options.Platforms,
+		TfGlobalOptions: tfOpts,
+	})
+}
+
+// ProvidersLockE records the checksums of the providers for each platform, and exports the updated
+// .terraform.lock.hcl to the module directory of the host.
+func ProvidersLockE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ProvidersLockOptions) (string, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersLockE(config.IacToolTerraform, &terraformcore.ProvidersLockArgsOptions{
+		Platforms:       options.Platforms,
+		TfGlobalOptions: tfOpts,
+	})
+}
+
+func ProvidersSchema(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (*dagger.Container, container.Runtime, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersSchema(config.IacToolTerraform)
+}
+
+// ProvidersSchemaE returns the schemas of the providers used by the module (terraform providers schema -json).
+func ProvidersSchemaE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions) (*terraformcore.ProvidersSchema, error) {
+	tfRun := terraformcore.NewTerraformRunner(td, tfOpts)
+
+	return tfRun.RunProvidersSchemaE(config.IacToolTerraform)
+}
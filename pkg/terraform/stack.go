@@ -0,0 +1,27 @@
+package terraform
+
+import (
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/terraformcore"
+)
+
+// ApplyStackE applies the root modules in dependency order: a module is applied once the modules it
+// depends on (keyed by module path) were applied. The modules of the same level run concurrently.
+// The dependencies can be declared, or inferred with discovery.InferDependencies.
+func ApplyStackE(td *terradagger.TD, modules []*terraformcore.TfOptions, dependencies map[string][]string,
+	applyOptions ApplyOptions, options ManyOptions) (terradagger.JobResults, error) {
+	options.Dependencies = dependencies
+	options.Reverse = false
+
+	return ApplyManyE(td, modules, applyOptions, options)
+}
+
+// DestroyStackE destroys the root modules in reverse dependency order: a module is destroyed once the
+// modules that depend on it were destroyed.
+func DestroyStackE(td *terradagger.TD, modules []*terraformcore.TfOptions, dependencies map[string][]string,
+	destroyOptions DestroyOptions, options ManyOptions) (terradagger.JobResults, error) {
+	options.Dependencies = dependencies
+	options.Reverse = true
+
+	return DestroyManyE(td, modules, destroyOptions, options)
+}
@@ -14,6 +14,11 @@ var (
 	tfApplyCommand    = "apply"
 	tfDestroyCommand  = "destroy"
 	tfValidateCommand = "validate"
+	tfShowCommand     = "show"
+
+	tfProvidersMirrorCommand = "providers mirror"
+	tfProvidersLockCommand   = "providers lock"
+	tfProvidersSchemaCommand = "providers schema"
 )
 
 type TfLifecycleCMD struct{}
@@ -25,6 +30,10 @@ type LifecycleCommandConfig interface {
 	GetApplyCommand() string
 	GetDestroyCommand() string
 	GetValidateCommand() string
+	GetShowCommand() string
+	GetProvidersMirrorCommand() string
+	GetProvidersLockCommand() string
+	GetProvidersSchemaCommand() string
 }
 
 func (t *TfLifecycleCMD) GetEntryPoint(iaacTool string) string {
@@ -59,6 +68,22 @@ func (t *TfLifecycleCMD) GetValidateCommand() string {
 	return tfValidateCommand
 }
 
+func (t *TfLifecycleCMD) GetShowCommand() string {
+	return tfShowCommand
+}
+
+func (t *TfLifecycleCMD) GetProvidersMirrorCommand() string {
+	return tfProvidersMirrorCommand
+}
+
+func (t *TfLifecycleCMD) GetProvidersLockCommand() string {
+	return tfProvidersLockCommand
+}
+
+func (t *TfLifecycleCMD) GetProvidersSchemaCommand() string {
+	return tfProvidersSchemaCommand
+}
+
 type GetTerraformLifecycleCMDStringOptions struct {
 	iacConfig        IacConfig
 	lifecycleCommand string
@@ -2,10 +2,12 @@ package terraformcore
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"dagger.io/dagger"
 
 	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/credentials"
 	"github.com/Excoriate/go-terradagger/pkg/terradagger"
 )
 
@@ -17,6 +19,10 @@ type TerraformContainerConfig interface {
 type TerraformContainerConfigOptions struct {
 	tfOptions TfGlobalOptions
 	iacConfig IacConfig
+	// credentials are resolved along with the container runtime, and injected with the env vars.
+	credentials []*credentials.Credentials
+	// withoutServices doesn't start (nor bind) the services, for the commands that don't use them.
+	withoutServices bool
 }
 
 func (t *TerraformContainerConfigOptions) GetTfOptions() TfGlobalOptions {
@@ -29,7 +35,7 @@ func (t *TerraformContainerConfigOptions) GetIacConfig() IacConfig {
 
 type TerraformContainerSetup interface {
 	getContainerImageCfg(td *terradagger.TD) container.Image
-	getContainerRuntime(td *terradagger.TD, imageCfg container.Image) container.Runtime
+	getContainerRuntime(td *terradagger.TD, imageCfg container.Image) (container.Runtime, error)
 	AddEnvVarsToTerraformContainer(td *terradagger.TD, runtime container.Runtime, tfContainer *dagger.Container) *dagger.Container
 }
 
@@ -47,22 +53,143 @@ func (t *TerraformContainerConfigOptions) getContainerImageCfg(td *terradagger.T
 }
 
 // getContainerCfg resolves the container configuration to use for the given IAC configuration and Terraform options.
-func (t *TerraformContainerConfigOptions) getContainerRuntime(td *terradagger.TD, imageCfg container.Image) container.Runtime {
+// The services of the options are started (and checked for readiness) the first time they're needed in the
+// terradagger session, and bound to the container. They're stopped when the session is closed.
+func (t *TerraformContainerConfigOptions) getContainerRuntime(td *terradagger.TD, imageCfg container.Image) (container.Runtime, error) {
+	mounts, err := getHostCredentialMounts(t.tfOptions, td.Config.GetHomeDir())
+	if err != nil {
+		return nil, err
+	}
+
+	sshKnownHosts, err := getSSHKnownHosts(t.tfOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	cliConfig, mirrorMounts, err := getProviderMirrorConfig(t.tfOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts = append(mounts, mirrorMounts...)
+
+	var caCertificates []string
+	for _, certificateFile := range t.tfOptions.GetCACertificates() {
+		certificate, err := container.ReadCACertificate(certificateFile)
+		if err != nil {
+			return nil, err
+		}
+
+		caCertificates = append(caCertificates, certificate)
+	}
+
+	for _, credential := range t.tfOptions.GetGitHTTPSCredentials() {
+		if err := credential.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	registryCredentials, err := getRegistryCredentials(t.tfOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if platform := t.tfOptions.GetPlatform(); platform != "" {
+		if err := container.ValidatePlatform(platform); err != nil {
+			return nil, err
+		}
+
+		container.WarnIfNotNativePlatform(td, platform)
+	}
+
+	t.credentials, err = credentials.Resolve(t.tfOptions.GetCredentialProviders())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resolved := range t.credentials {
+		mounts = append(mounts, resolved.Mounts...)
+	}
+
+	var serviceBindings []container.ServiceBinding
+	if !t.withoutServices {
+		startedBindings, err := container.StartServices(td, t.tfOptions.GetServices())
+		if err != nil {
+			return nil, err
+		}
+
+		serviceBindings = append(serviceBindings, t.tfOptions.GetServiceBindings()...)
+		serviceBindings = append(serviceBindings, startedBindings...)
+	}
+
+	lockFile := filepath.Join(td.Config.GetWorkspaceAbs(), td.Config.GetTerraDaggerDir(), container.ImageLockFile)
+
+	var baseContainer *dagger.Container
+	if toolchain := t.tfOptions.GetToolchain(); toolchain != nil {
+		// The toolchain is built for the platform of the container, unless it has its own.
+		if toolchain.Platform == "" && t.tfOptions.GetPlatform() != "" {
+			platformToolchain := *toolchain
+			platformToolchain.Platform = t.tfOptions.GetPlatform()
+			toolchain = &platformToolchain
+		}
+
+		// The toolchain is reproducible, so its base image is always pinned.
+		baseContainer, err = container.BuildToolchain(td, toolchain, container.ToolchainBuildOptions{
+			ImageLockFile:       lockFile,
+			StrictImageDigests:  t.tfOptions.IsStrictImageDigests(),
+			RegistryCredentials: registryCredentials,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if t.tfOptions.IsPinImageDigests() {
+		pinnedImage, err := container.PinImage(td, imageCfg.GetTerraformContainerImage(), t.tfOptions.GetPlatform(), lockFile, t.tfOptions.IsStrictImageDigests(), registryCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		td.Log.Info(fmt.Sprintf("using the image pinned by digest: %s", pinnedImage))
+		imageCfg = container.NewImageConfig(pinnedImage, "")
+	}
+
 	containerCfg := container.Config{
-		MountPathAbs:         td.Config.GetWorkspaceAbs(),
-		Workdir:              t.tfOptions.GetModulePath(),
-		ContainerImage:       imageCfg,
-		KeepEntryPoint:       false,                                // This will override the container's entrypoint with the command we want to run.
-		AddPrivateGitSupport: t.tfOptions.GetEnableSSHPrivateGit(), // Add support for private git repos.
+		MountPathAbs:          td.Config.GetWorkspaceAbs(),
+		Workdir:               t.tfOptions.GetModulePath(),
+		ContainerImage:        imageCfg,
+		KeepEntryPoint:        false,                                // This will override the container's entrypoint with the command we want to run.
+		AddPrivateGitSupport:  t.tfOptions.GetEnableSSHPrivateGit(), // Add support for private git repos.
+		StepLog:               t.tfOptions.GetStepLog(),
+		ServiceBindings:       serviceBindings,
+		Mounts:                mounts,
+		GitHTTPSCredentials:   t.tfOptions.GetGitHTTPSCredentials(),
+		GitURLRewrites:        t.tfOptions.GetGitURLRewrites(),
+		SSHKnownHosts:         sshKnownHosts,
+		StrictHostKeyChecking: t.tfOptions.IsSSHStrictHostKeyChecking(),
+		CACertificates:        caCertificates,
+		Proxy:                 t.tfOptions.GetProxy(),
+		TerraformCLIConfig:    cliConfig,
+		BaseContainer:         baseContainer,
+		RegistryCredentials:   registryCredentials,
+		Platform:              t.tfOptions.GetPlatform(),
 	}
 
-	return container.New(&containerCfg, td)
+	return container.New(&containerCfg, td), nil
 }
 
 // AddEnvVarsToTerraformContainer configures the container with the appropriate environment variables.
 func (t *TerraformContainerConfigOptions) AddEnvVarsToTerraformContainer(td *terradagger.TD, runtime container.Runtime, tfContainer *dagger.Container) *dagger.Container {
 	tfOpts := t.GetTfOptions()
 
+	// Inject the credentials resolved by the credential providers; the secrets are never logged.
+	for _, resolved := range t.credentials {
+		td.Log.Info(fmt.Sprintf("injecting credentials %s", resolved.GetSummary()))
+		tfContainer = runtime.AddEnvVars(resolved.EnvVars, tfContainer)
+		tfContainer = runtime.AddSecretEnvVars(resolved.SecretEnvVars, tfContainer)
+	}
+
+	// The sensitive backend config values are read by the -backend-config arguments of init.
+	tfContainer = runtime.AddSecretEnvVars(getBackendConfigSecretEnvVars(tfOpts.GetBackendConfig()), tfContainer)
+
 	// Mirror all host environment variables if specified.
 	if tfOpts.IsMirrorAllEnvVarsFromHost() {
 		return runtime.AddEnvVars(td.Config.GetHostEnvVars(), tfContainer)
@@ -84,5 +211,11 @@ func (t *TerraformContainerConfigOptions) AddEnvVarsToTerraformContainer(td *ter
 		tfContainer = runtime.AddEnvVars(td.Config.GetTerraformEnvVars(), tfContainer)
 	}
 
+	// Use the AWS profile of the options, or the one of the host when its AWS config is mounted.
+	hostProfile := td.Config.GetEnvVarsByKeys([]string{awsProfileEnvVar})[awsProfileEnvVar]
+	if profile := getAWSProfile(tfOpts, hostProfile); profile != "" {
+		tfContainer = runtime.AddEnvVars(map[string]string{awsProfileEnvVar: profile}, tfContainer)
+	}
+
 	return tfContainer
 }
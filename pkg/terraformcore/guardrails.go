@@ -0,0 +1,176 @@
+package terraformcore
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
+)
+
+const guardrailsPlanFile = "terradagger-guardrails.tfplan"
+
+// Guardrails are evaluated against the plan before running apply or destroy. If the plan
+// violates any of them, the command is refused unless Override is set.
+type Guardrails struct {
+	// ProtectedAddresses is a list of addresses, or patterns (path.Match syntax) matched against the resource
+	// addresses, e.g. module.database.*, aws_s3_bucket.state or aws_instance.web[0]. The brackets of the
+	// indexed addresses (module.db["primary"].*) also match literally.
+	ProtectedAddresses []string
+	// ProtectedTypes is a list of resource types that can't be destroyed or replaced, e.g. aws_rds_cluster
+	ProtectedTypes []string
+	// MaxDeletions is the maximum number of resources that can be destroyed or replaced. Zero disables the check
+	MaxDeletions int
+	// Override is a flag to explicitly allow the destructive changes, even if the guardrails are violated
+	Override bool
+}
+
+// AreValid checks that the guardrails patterns and thresholds are well-formed.
+func (g *Guardrails) AreValid() error {
+	for _, pattern := range g.ProtectedAddresses {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return erroer.NewErrTerraformCoreInvalidArgumentError(fmt.Sprintf("the protected address pattern %s is not valid", pattern), err)
+		}
+	}
+
+	if g.MaxDeletions < 0 {
+		return erroer.NewErrTerraformCoreInvalidArgumentError("the max deletions threshold cannot be negative", nil)
+	}
+
+	return nil
+}
+
+func (g *Guardrails) isProtected(rc *TfResourceChange) (bool, string) {
+	for _, t := range g.ProtectedTypes {
+		if rc.Type == t {
+			return true, fmt.Sprintf("type %s is protected", t)
+		}
+	}
+
+	for _, pattern := range g.ProtectedAddresses {
+		if matchAddress(pattern, rc.Address) {
+			return true, fmt.Sprintf("address matches the protected pattern %s", pattern)
+		}
+	}
+
+	return false, ""
+}
+
+// addressBracketsEscaper escapes the brackets of a pattern, so they match the index of an address instead
+// of being a character class.
+var addressBracketsEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// matchAddress checks whether the resource address is the pattern, or matches it. In path.Match syntax,
+// [...] is a character class, so the pattern is also matched with its brackets taken literally, e.g.
+// module.db["primary"].* matches module.db["primary"].aws_rds_cluster.this
+func matchAddress(pattern, address string) bool {
+	if pattern == address {
+		return true
+	}
+
+	if matched, _ := path.Match(pattern, address); matched {
+		return true
+	}
+
+	matched, _ := path.Match(addressBracketsEscaper.Replace(pattern), address)
+
+	return matched
+}
+
+// GetViolations returns a human-readable description of every guardrail that the plan violates.
+func (g *Guardrails) GetViolations(plan *TfPlanJSON) []string {
+	var violations []string
+	deletions := plan.GetDeletions()
+
+	for idx := range deletions {
+		rc := &deletions[idx]
+		protected, reason := g.isProtected(rc)
+		if !protected {
+			continue
+		}
+
+		action := "destroyed"
+		if rc.IsReplace() {
+			action = "replaced"
+		}
+
+		violations = append(violations, fmt.Sprintf("%s would be %s (%s)", rc.Address, action, reason))
+	}
+
+	if g.MaxDeletions > 0 && len(deletions) > g.MaxDeletions {
+		violations = append(violations, fmt.Sprintf("the plan destroys or replaces %d resources, exceeding the allowed maximum of %d", len(deletions), g.MaxDeletions))
+	}
+
+	return violations
+}
+
+// Check returns an ErrTerraformCoreGuardrailViolationError if the plan violates the guardrails.
+func (g *Guardrails) Check(plan *TfPlanJSON) error {
+	violations := g.GetViolations(plan)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return erroer.NewErrTerraformCoreGuardrailViolationError("the plan contains destructive changes", violations)
+}
+
+// applyWithGuardrails saves a plan in the container, inspects it against the guardrails, and (if
+// allowed) returns the container with the saved plan applied, using the given apply arguments. Applying the saved plan ensures that
+// what's applied is exactly what was inspected. The apply arguments include -auto-approve, which the guardrails require.
+func (i *IasC) applyWithGuardrails(td *terradagger.TD, runtime container.Runtime, tfContainer *dagger.Container, guardrails *Guardrails, planArgs, applyArgs []string) (*dagger.Container, error) {
+	tfLifeCycleCmd := TfLifecycleCMD{}
+
+	planCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetPlanCommand(),
+		args:             utils.MergeSlices(planArgs, []string{"-input=false", fmt.Sprintf("-out=%s", guardrailsPlanFile)}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	showCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetShowCommand(),
+		args:             []string{"-json", guardrailsPlanFile},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	applyCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetApplyCommand(),
+		args:             utils.MergeSlices([]string{"-input=false"}, applyArgs, []string{guardrailsPlanFile}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	td.Log.Info(fmt.Sprintf("guardrails are enabled, inspecting the plan before applying it: %s", planCMDStr))
+
+	tfContainer = runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(planCMDStr)}, tfContainer)
+	showOut, err := runtime.RunAndGetStdout(runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(showCMDStr)}, tfContainer))
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := ParsePlanJSON(showOut)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := guardrails.Check(plan); err != nil {
+		if !guardrails.Override {
+			return nil, err
+		}
+
+		td.Log.Warn(fmt.Sprintf("guardrails were explicitly overridden, continuing: %s", err.Error()))
+	}
+
+	return runtime.AddCommands([]container.Command{terradagger.BuildInterruptibleCMDWithSH(applyCMDStr, td.GetInterruptAt(), td.GetInterruptMarkerFile())}, tfContainer), nil
+}
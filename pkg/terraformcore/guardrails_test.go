@@ -0,0 +1,83 @@
+package terraformcore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/stretchr/testify/assert"
+)
+
+const guardrailsTestPlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.0",
+  "resource_changes": [
+    {"address": "aws_s3_bucket.state", "type": "aws_s3_bucket", "name": "state", "change": {"actions": ["delete"]}},
+    {"address": "module.db.aws_rds_cluster.this", "type": "aws_rds_cluster", "name": "this", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_instance.web[0]", "type": "aws_instance", "name": "web", "index": 0, "change": {"actions": ["delete"]}},
+    {"address": "random_string.suffix", "type": "random_string", "name": "suffix", "change": {"actions": ["create"]}},
+    {"address": "null_resource.noop", "type": "null_resource", "name": "noop", "change": {"actions": ["no-op"]}}
+  ]
+}`
+
+func TestGuardrails_GetViolations(t *testing.T) {
+	plan, err := ParsePlanJSON(guardrailsTestPlan)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		guardrails Guardrails
+		want       int
+	}{
+		{"no guardrails", Guardrails{}, 0},
+		{"protected type", Guardrails{ProtectedTypes: []string{"aws_s3_bucket"}}, 1},
+		{"protected address pattern", Guardrails{ProtectedAddresses: []string{"module.db.*"}}, 1},
+		{"unmatched protections", Guardrails{ProtectedTypes: []string{"aws_iam_role"}, ProtectedAddresses: []string{"module.network.*"}}, 0},
+		{"protected indexed address", Guardrails{ProtectedAddresses: []string{"aws_instance.web[0]"}}, 1},
+		{"deletions under threshold", Guardrails{MaxDeletions: 3}, 0},
+		{"deletions over threshold", Guardrails{MaxDeletions: 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.guardrails.GetViolations(plan), tt.want)
+		})
+	}
+}
+
+func TestGuardrails_Check(t *testing.T) {
+	plan, err := ParsePlanJSON(guardrailsTestPlan)
+	assert.NoError(t, err)
+
+	g := &Guardrails{ProtectedTypes: []string{"aws_rds_cluster"}}
+	checkErr := g.Check(plan)
+
+	var violationErr *erroer.ErrTerraformCoreGuardrailViolationError
+	assert.True(t, errors.As(checkErr, &violationErr))
+	assert.Equal(t, []string{"module.db.aws_rds_cluster.this would be replaced (type aws_rds_cluster is protected)"}, violationErr.Violations)
+}
+
+func TestMatchAddress(t *testing.T) {
+	assert.True(t, matchAddress("aws_instance.web[0]", "aws_instance.web[0]"))
+	assert.True(t, matchAddress(`module.db["primary"]`, `module.db["primary"]`))
+	assert.True(t, matchAddress(`module.db["primary"].*`, `module.db["primary"].aws_rds_cluster.this`))
+	assert.True(t, matchAddress("aws_instance.web[*]", "aws_instance.web[1]"))
+	assert.True(t, matchAddress("module.db.*", "module.db.aws_rds_cluster.this"))
+	assert.False(t, matchAddress("aws_instance.web[0]", "aws_instance.web[1]"))
+	assert.False(t, matchAddress(`module.db["primary"].*`, `module.db["replica"].aws_rds_cluster.this`))
+}
+
+func TestGuardrails_AreValid(t *testing.T) {
+	assert.NoError(t, (&Guardrails{ProtectedAddresses: []string{"module.*"}}).AreValid())
+	assert.Error(t, (&Guardrails{ProtectedAddresses: []string{"module.["}}).AreValid())
+	assert.Error(t, (&Guardrails{MaxDeletions: -1}).AreValid())
+}
+
+func TestGuardrails_RequireAutoApprove(t *testing.T) {
+	guardrails := &Guardrails{MaxDeletions: 1}
+
+	assert.Error(t, (&ApplyArgsOptions{Guardrails: guardrails}).AreValid())
+	assert.NoError(t, (&ApplyArgsOptions{Guardrails: guardrails, AutoApprove: true}).AreValid())
+	assert.Error(t, (&DestroyArgsOptions{Guardrails: guardrails, JSON: true}).AreValid())
+	assert.NoError(t, (&DestroyArgsOptions{Guardrails: guardrails, AutoApprove: true, JSON: true}).AreValid())
+}
@@ -0,0 +1,62 @@
+package terraformcore
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const awsProfileEnvVar = "AWS_PROFILE"
+
+// getHostCredentialMounts returns the cloud credential directories of the host that are mounted into
+// the container (at the same place, relative to the home directory), and checks that they exist.
+func getHostCredentialMounts(tfOpts TfGlobalOptions, homeDir string) ([]container.Mount, error) {
+	var mounts []container.Mount
+
+	if tfOpts.IsMountAWSConfigFromHost() {
+		mounts = append(mounts, getHomeDirMount(homeDir, ".aws", []string{"cli/cache"}))
+	}
+
+	if tfOpts.IsMountGCloudConfigFromHost() {
+		mounts = append(mounts, getHomeDirMount(homeDir, filepath.Join(".config", "gcloud"), []string{"logs"}))
+	}
+
+	if tfOpts.IsMountAzureConfigFromHost() {
+		mounts = append(mounts, getHomeDirMount(homeDir, ".azure", []string{"logs", "commands", "telemetry", "cliextensions"}))
+	}
+
+	for _, mount := range mounts {
+		if err := mount.Validate(); err != nil {
+			return nil, erroer.NewErrTerraformCoreInvalidConfigurationError(
+				fmt.Sprintf("the credentials directory %s doesn't exist in the host", mount.HostPath), err)
+		}
+	}
+
+	return mounts, nil
+}
+
+func getHomeDirMount(homeDir, relPath string, exclude []string) container.Mount {
+	return container.Mount{
+		HostPath:      filepath.Join(homeDir, relPath),
+		ContainerPath: path.Join(container.HomeDir, filepath.ToSlash(relPath)),
+		Secret:        true,
+		Exclude:       exclude,
+	}
+}
+
+// getAWSProfile returns the AWS profile to use in the container: the one of the options, or the one of
+// the host (hostProfile) if the AWS config is mounted.
+func getAWSProfile(tfOpts TfGlobalOptions, hostProfile string) string {
+	if tfOpts.GetAWSProfile() != "" {
+		return tfOpts.GetAWSProfile()
+	}
+
+	if tfOpts.IsMountAWSConfigFromHost() {
+		return hostProfile
+	}
+
+	return ""
+}
@@ -0,0 +1,34 @@
+package terraformcore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostCredentialMounts(t *testing.T) {
+	homeDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".aws"), 0o755))
+
+	mounts, err := getHostCredentialMounts(WithOptions(nil, &TfOptions{MountAWSConfigFromHost: true}), homeDir)
+	assert.NoError(t, err)
+	assert.Len(t, mounts, 1)
+	assert.Equal(t, filepath.Join(homeDir, ".aws"), mounts[0].HostPath)
+	assert.Equal(t, "/root/.aws", mounts[0].ContainerPath)
+	assert.True(t, mounts[0].Secret)
+
+	_, err = getHostCredentialMounts(WithOptions(nil, &TfOptions{MountGCloudConfigFromHost: true}), homeDir)
+	assert.Error(t, err)
+
+	mounts, err = getHostCredentialMounts(WithOptions(nil, &TfOptions{}), homeDir)
+	assert.NoError(t, err)
+	assert.Empty(t, mounts)
+}
+
+func TestGetAWSProfile(t *testing.T) {
+	assert.Equal(t, "", getAWSProfile(WithOptions(nil, &TfOptions{}), "dev"))
+	assert.Equal(t, "dev", getAWSProfile(WithOptions(nil, &TfOptions{MountAWSConfigFromHost: true}), "dev"))
+	assert.Equal(t, "prod", getAWSProfile(WithOptions(nil, &TfOptions{MountAWSConfigFromHost: true, AWSProfile: "prod"}), "dev"))
+}
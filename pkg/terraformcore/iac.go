@@ -12,10 +12,18 @@ type IacLifeCycleCommand interface {
 	InitE(td *terradagger.TD, tfOpts TfGlobalOptions, options InitArgs, extraArgs []string) (string, error)
 	Plan(td *terradagger.TD, tfOpts TfGlobalOptions, options PlanArgs, extraArgs []string) (*dagger.Container, container.Runtime, error)
 	PlanE(td *terradagger.TD, tfOpts TfGlobalOptions, options PlanArgs, extraArgs []string) (string, error)
+	PlanResultE(td *terradagger.TD, tfOpts TfGlobalOptions, options PlanArgs, extraArgs []string) (*PlanResult, error)
 	Apply(td *terradagger.TD, tfOpts TfGlobalOptions, options ApplyArgs, extraArgs []string) (*dagger.Container, container.Runtime, error)
 	ApplyE(td *terradagger.TD, tfOpts TfGlobalOptions, options ApplyArgs, extraArgs []string) (string, error)
+	ApplyAndVerifyIdempotentE(td *terradagger.TD, tfOpts TfGlobalOptions, options ApplyArgs, extraArgs []string) (string, error)
 	Destroy(td *terradagger.TD, tfOpts TfGlobalOptions, options DestroyArgs, extraArgs []string) (*dagger.Container, container.Runtime, error)
 	DestroyE(td *terradagger.TD, tfOpts TfGlobalOptions, options DestroyArgs, extraArgs []string) (string, error)
+	ProvidersMirror(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersMirrorArgs, extraArgs []string) (*dagger.Container, container.Runtime, error)
+	ProvidersMirrorE(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersMirrorArgs, extraArgs []string) (string, error)
+	ProvidersLock(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersLockArgs, extraArgs []string) (*dagger.Container, container.Runtime, error)
+	ProvidersLockE(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersLockArgs, extraArgs []string) (string, error)
+	ProvidersSchema(td *terradagger.TD, tfOpts TfGlobalOptions, extraArgs []string) (*dagger.Container, container.Runtime, error)
+	ProvidersSchemaE(td *terradagger.TD, tfOpts TfGlobalOptions, extraArgs []string) (*ProvidersSchema, error)
 }
 
 type IacConfigOptions struct {
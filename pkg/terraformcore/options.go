@@ -3,6 +3,9 @@ package terraformcore
 import (
 	"path/filepath"
 
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/credentials"
+
 	"github.com/Excoriate/go-terradagger/pkg/config"
 
 	"github.com/Excoriate/go-terradagger/pkg/terradagger"
@@ -33,6 +36,72 @@ type TfOptions struct {
 	InvalidateCache bool
 	// EnvVarsToInjectByKeyFromHost is a slice of environment variables to inject into the container
 	EnvVarsToInjectByKeyFromHost []string
+	// StepLog, if set, receives the stdout/stderr of each step (init, plan, apply, etc.) while it runs, line
+	// by line. The steps with a step log aren't cached between runs
+	StepLog *container.StepLog
+	// ServiceBindings are the services (e.g. an S3-compatible server) reachable from the terraform container,
+	// using their alias as the hostname
+	ServiceBindings []container.ServiceBinding
+	// Services are sidecars (e.g. LocalStack, a database) started before the command, and bound to the
+	// terraform container. They run until the terradagger session is closed
+	Services []container.Service
+	// BackendConfig is the backend configuration (-backend-config key=value) passed to every init, including the
+	// ones that run before plan, apply and destroy. The secrets (access_key, secret_key, token, password,
+	// client_secret and sas_token) are passed as Dagger secrets
+	BackendConfig map[string]string
+	// MountAWSConfigFromHost mounts ~/.aws (config, credentials and the SSO cache) into the container, as secrets
+	MountAWSConfigFromHost bool
+	// AWSProfile is the AWS profile used in the container (AWS_PROFILE). If it's empty, and the AWS config is
+	// mounted, the AWS_PROFILE of the host is passed through
+	AWSProfile string
+	// MountGCloudConfigFromHost mounts ~/.config/gcloud (including the application default credentials) into the container, as secrets
+	MountGCloudConfigFromHost bool
+	// MountAzureConfigFromHost mounts ~/.azure into the container, as secrets
+	MountAzureConfigFromHost bool
+	// CredentialProviders resolve the cloud credentials in the host (e.g. &credentials.AWSStaticKeys{}), and
+	// inject the minimal set of env vars and files into the container, as secrets
+	CredentialProviders []credentials.Provider
+	// GitHTTPSCredentials are the tokens used to clone the private modules with git::https:// sources,
+	// written to the ~/.netrc of the container as a secret
+	GitHTTPSCredentials []container.GitHTTPSCredential
+	// GitURLRewrites are the git url.<base>.insteadOf rewrites, keyed by the URL prefix to rewrite, e.g.
+	// {"git@github.com:": "https://github.com/"} to clone SSH sources with HTTPS
+	GitURLRewrites map[string]string
+	// SSHKnownHostsFile is a known_hosts file of the host, used to verify the keys of the SSH git hosts
+	SSHKnownHostsFile string
+	// SSHKnownHosts are known_hosts entries, e.g. "github.com ssh-ed25519 AAAA...", added to SSHKnownHostsFile
+	SSHKnownHosts []string
+	// SSHStrictHostKeyChecking rejects the SSH git hosts that aren't known. By default, the keys of the
+	// unknown hosts are accepted
+	SSHStrictHostKeyChecking bool
+	// CACertificates are PEM files of the host with extra CA certificates (e.g. of a corporate proxy), added
+	// to the trust store of the container
+	CACertificates []string
+	// Proxy is the HTTP proxy passed to terraform, terragrunt, the providers and git. Use
+	// container.GetProxyFromHost() to use the proxy of the host
+	Proxy *container.Proxy
+	// ProviderMirror, if set, generates a CLI configuration (.terraformrc) that installs the providers from
+	// a filesystem mirror of the host, and/or a network mirror
+	ProviderMirror *ProviderMirror
+	// Toolchain, if set, builds the image from a base image plus pinned versions of terraform, terragrunt
+	// and tflint, instead of using CustomContainerImage or the default image
+	Toolchain *container.Toolchain
+	// PinImageDigests records the digest of the image in .terradagger/images.lock.json the first time, and
+	// then always runs the image pinned by that digest. Commit the lock file to make the pipelines reproducible
+	PinImageDigests bool
+	// StrictImageDigests refuses to run when the resolved digest isn't the one of the lock file. It
+	// implies PinImageDigests
+	StrictImageDigests bool
+	// RegistryCredentials authenticate the pull of the image from private registries. The passwords (or
+	// tokens) are passed as secrets
+	RegistryCredentials []container.RegistryCredential
+	// DockerConfigFile is a docker config.json of the host, whose registry credentials (auths) are added to
+	// RegistryCredentials. Use container.GetDefaultDockerConfigFile() for the one of the host
+	DockerConfigFile string
+	// Platform is the platform of the container, e.g. linux/amd64, so every machine (e.g. Apple Silicon
+	// laptops and amd64 CI runners) resolves the same provider binaries. It's also the default platform
+	// of providers lock and providers mirror, and of the Toolchain
+	Platform string
 }
 
 type TfGlobalOptions interface {
@@ -47,6 +116,29 @@ type TfGlobalOptions interface {
 	IsAutoDetectAWSKeysFromHost() bool
 	IsMirrorAllEnvVarsFromHost() bool
 	GetEnvVarsToInjectByKeyFromHost() []string
+	GetStepLog() *container.StepLog
+	GetServiceBindings() []container.ServiceBinding
+	GetServices() []container.Service
+	GetBackendConfig() map[string]string
+	IsMountAWSConfigFromHost() bool
+	GetAWSProfile() string
+	IsMountGCloudConfigFromHost() bool
+	IsMountAzureConfigFromHost() bool
+	GetCredentialProviders() []credentials.Provider
+	GetGitHTTPSCredentials() []container.GitHTTPSCredential
+	GetGitURLRewrites() map[string]string
+	GetSSHKnownHostsFile() string
+	GetSSHKnownHosts() []string
+	IsSSHStrictHostKeyChecking() bool
+	GetCACertificates() []string
+	GetProxy() *container.Proxy
+	GetProviderMirror() *ProviderMirror
+	GetToolchain() *container.Toolchain
+	IsPinImageDigests() bool
+	IsStrictImageDigests() bool
+	GetRegistryCredentials() []container.RegistryCredential
+	GetDockerConfigFile() string
+	GetPlatform() string
 	TfGlobalValidator
 }
 
@@ -102,3 +194,95 @@ func (o *tfOptions) IsMirrorAllEnvVarsFromHost() bool {
 func (o *tfOptions) GetEnvVarsToInjectByKeyFromHost() []string {
 	return o.options.EnvVarsToInjectByKeyFromHost
 }
+
+func (o *tfOptions) GetStepLog() *container.StepLog {
+	return o.options.StepLog
+}
+
+func (o *tfOptions) GetServiceBindings() []container.ServiceBinding {
+	return o.options.ServiceBindings
+}
+
+func (o *tfOptions) GetServices() []container.Service {
+	return o.options.Services
+}
+
+func (o *tfOptions) GetBackendConfig() map[string]string {
+	return o.options.BackendConfig
+}
+
+func (o *tfOptions) IsMountAWSConfigFromHost() bool {
+	return o.options.MountAWSConfigFromHost
+}
+
+func (o *tfOptions) GetAWSProfile() string {
+	return o.options.AWSProfile
+}
+
+func (o *tfOptions) IsMountGCloudConfigFromHost() bool {
+	return o.options.MountGCloudConfigFromHost
+}
+
+func (o *tfOptions) IsMountAzureConfigFromHost() bool {
+	return o.options.MountAzureConfigFromHost
+}
+
+func (o *tfOptions) GetCredentialProviders() []credentials.Provider {
+	return o.options.CredentialProviders
+}
+
+func (o *tfOptions) GetGitHTTPSCredentials() []container.GitHTTPSCredential {
+	return o.options.GitHTTPSCredentials
+}
+
+func (o *tfOptions) GetGitURLRewrites() map[string]string {
+	return o.options.GitURLRewrites
+}
+
+func (o *tfOptions) GetSSHKnownHostsFile() string {
+	return o.options.SSHKnownHostsFile
+}
+
+func (o *tfOptions) GetSSHKnownHosts() []string {
+	return o.options.SSHKnownHosts
+}
+
+func (o *tfOptions) IsSSHStrictHostKeyChecking() bool {
+	return o.options.SSHStrictHostKeyChecking
+}
+
+func (o *tfOptions) GetCACertificates() []string {
+	return o.options.CACertificates
+}
+
+func (o *tfOptions) GetProxy() *container.Proxy {
+	return o.options.Proxy
+}
+
+func (o *tfOptions) GetProviderMirror() *ProviderMirror {
+	return o.options.ProviderMirror
+}
+
+func (o *tfOptions) GetToolchain() *container.Toolchain {
+	return o.options.Toolchain
+}
+
+func (o *tfOptions) IsPinImageDigests() bool {
+	return o.options.PinImageDigests || o.options.StrictImageDigests
+}
+
+func (o *tfOptions) IsStrictImageDigests() bool {
+	return o.options.StrictImageDigests
+}
+
+func (o *tfOptions) GetRegistryCredentials() []container.RegistryCredential {
+	return o.options.RegistryCredentials
+}
+
+func (o *tfOptions) GetDockerConfigFile() string {
+	return o.options.DockerConfigFile
+}
+
+func (o *tfOptions) GetPlatform() string {
+	return o.options.Platform
+}
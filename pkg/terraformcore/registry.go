@@ -0,0 +1,31 @@
+package terraformcore
+
+import (
+	"github.com/Excoriate/go-terradagger/pkg/container"
+)
+
+// getRegistryCredentials returns the credentials used to pull the image: the ones of the options, and
+// the ones of the docker config file, if set. The credentials of the options are added last, so they
+// take precedence for the same registry.
+func getRegistryCredentials(tfOpts TfGlobalOptions) ([]container.RegistryCredential, error) {
+	var registryCredentials []container.RegistryCredential
+
+	if dockerConfigFile := tfOpts.GetDockerConfigFile(); dockerConfigFile != "" {
+		fromDockerConfig, err := container.GetRegistryCredentialsFromDockerConfig(dockerConfigFile)
+		if err != nil {
+			return nil, err
+		}
+
+		registryCredentials = append(registryCredentials, fromDockerConfig...)
+	}
+
+	for _, credential := range tfOpts.GetRegistryCredentials() {
+		if err := credential.Validate(); err != nil {
+			return nil, err
+		}
+
+		registryCredentials = append(registryCredentials, credential)
+	}
+
+	return registryCredentials, nil
+}
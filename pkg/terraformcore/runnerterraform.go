@@ -11,10 +11,18 @@ type TerraformRunner interface {
 	RunInitE(binary string, options *InitArgsOptions) (string, error)
 	RunPlan(binary string, options *PlanArgsOptions) (*dagger.Container, container.Runtime, error)
 	RunPlanE(binary string, options *PlanArgsOptions) (string, error)
+	RunPlanResultE(binary string, options *PlanArgsOptions) (*PlanResult, error)
 	RunApply(binary string, options *ApplyArgsOptions) (*dagger.Container, container.Runtime, error)
 	RunApplyE(binary string, options *ApplyArgsOptions) (string, error)
+	RunApplyAndVerifyIdempotentE(binary string, options *ApplyArgsOptions) (string, error)
 	RunDestroy(binary string, options *DestroyArgsOptions) (*dagger.Container, container.Runtime, error)
 	RunDestroyE(binary string, options *DestroyArgsOptions) (string, error)
+	RunProvidersMirror(binary string, options *ProvidersMirrorArgsOptions) (*dagger.Container, container.Runtime, error)
+	RunProvidersMirrorE(binary string, options *ProvidersMirrorArgsOptions) (string, error)
+	RunProvidersLock(binary string, options *ProvidersLockArgsOptions) (*dagger.Container, container.Runtime, error)
+	RunProvidersLockE(binary string, options *ProvidersLockArgsOptions) (string, error)
+	RunProvidersSchema(binary string) (*dagger.Container, container.Runtime, error)
+	RunProvidersSchemaE(binary string) (*ProvidersSchema, error)
 }
 
 type TerraformRunnerOptions struct {
@@ -67,6 +75,14 @@ func (t *TerraformRunnerOptions) RunPlanE(binary string, args *PlanArgsOptions)
 	return tfIaac.PlanE(t.td, t.TfGlobalOptions, args, []string{})
 }
 
+func (t *TerraformRunnerOptions) RunPlanResultE(binary string, args *PlanArgsOptions) (*PlanResult, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.PlanResultE(t.td, t.TfGlobalOptions, args, []string{})
+}
+
 func (t *TerraformRunnerOptions) RunApply(binary string, args *ApplyArgsOptions) (*dagger.Container, container.Runtime, error) {
 	tfIaac := IasC{
 		Config: getIaacConfigByBinary(binary),
@@ -83,6 +99,14 @@ func (t *TerraformRunnerOptions) RunApplyE(binary string, args *ApplyArgsOptions
 	return tfIaac.ApplyE(t.td, t.TfGlobalOptions, args, []string{})
 }
 
+func (t *TerraformRunnerOptions) RunApplyAndVerifyIdempotentE(binary string, args *ApplyArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ApplyAndVerifyIdempotentE(t.td, t.TfGlobalOptions, args, []string{})
+}
+
 func (t *TerraformRunnerOptions) RunDestroy(binary string, args *DestroyArgsOptions) (*dagger.Container, container.Runtime, error) {
 	tfIaac := IasC{
 		Config: getIaacConfigByBinary(binary),
@@ -98,3 +122,51 @@ func (t *TerraformRunnerOptions) RunDestroyE(binary string, args *DestroyArgsOpt
 
 	return tfIaac.DestroyE(t.td, t.TfGlobalOptions, args, []string{})
 }
+
+func (t *TerraformRunnerOptions) RunProvidersMirror(binary string, args *ProvidersMirrorArgsOptions) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersMirror(t.td, t.TfGlobalOptions, args, []string{})
+}
+
+func (t *TerraformRunnerOptions) RunProvidersMirrorE(binary string, args *ProvidersMirrorArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersMirrorE(t.td, t.TfGlobalOptions, args, []string{})
+}
+
+func (t *TerraformRunnerOptions) RunProvidersLock(binary string, args *ProvidersLockArgsOptions) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersLock(t.td, t.TfGlobalOptions, args, []string{})
+}
+
+func (t *TerraformRunnerOptions) RunProvidersLockE(binary string, args *ProvidersLockArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersLockE(t.td, t.TfGlobalOptions, args, []string{})
+}
+
+func (t *TerraformRunnerOptions) RunProvidersSchema(binary string) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersSchema(t.td, t.TfGlobalOptions, []string{})
+}
+
+func (t *TerraformRunnerOptions) RunProvidersSchemaE(binary string) (*ProvidersSchema, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersSchemaE(t.td, t.TfGlobalOptions, []string{})
+}
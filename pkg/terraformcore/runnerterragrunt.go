@@ -52,6 +52,14 @@ func (tg *TerragruntRunnerOptions) RunPlanE(binary string, args *PlanArgsOptions
 	return tfIaac.PlanE(tg.td, tg.TfGlobalOptions, args, []string{})
 }
 
+func (tg *TerragruntRunnerOptions) RunPlanResultE(binary string, args *PlanArgsOptions) (*PlanResult, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.PlanResultE(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
 func (tg *TerragruntRunnerOptions) RunApply(binary string, args *ApplyArgsOptions) (*dagger.Container, container.Runtime, error) {
 	tfIaac := IasC{
 		Config: getIaacConfigByBinary(binary),
@@ -68,6 +76,14 @@ func (tg *TerragruntRunnerOptions) RunApplyE(binary string, args *ApplyArgsOptio
 	return tfIaac.ApplyE(tg.td, tg.TfGlobalOptions, args, []string{})
 }
 
+func (tg *TerragruntRunnerOptions) RunApplyAndVerifyIdempotentE(binary string, args *ApplyArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ApplyAndVerifyIdempotentE(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
 func (tg *TerragruntRunnerOptions) RunDestroy(binary string, args *DestroyArgsOptions) (*dagger.Container, container.Runtime, error) {
 	tfIaac := IasC{
 		Config: getIaacConfigByBinary(binary),
@@ -83,3 +99,51 @@ func (tg *TerragruntRunnerOptions) RunDestroyE(binary string, args *DestroyArgsO
 
 	return tfIaac.DestroyE(tg.td, tg.TfGlobalOptions, args, []string{})
 }
+
+func (tg *TerragruntRunnerOptions) RunProvidersMirror(binary string, args *ProvidersMirrorArgsOptions) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersMirror(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
+func (tg *TerragruntRunnerOptions) RunProvidersMirrorE(binary string, args *ProvidersMirrorArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersMirrorE(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
+func (tg *TerragruntRunnerOptions) RunProvidersLock(binary string, args *ProvidersLockArgsOptions) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersLock(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
+func (tg *TerragruntRunnerOptions) RunProvidersLockE(binary string, args *ProvidersLockArgsOptions) (string, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersLockE(tg.td, tg.TfGlobalOptions, args, []string{})
+}
+
+func (tg *TerragruntRunnerOptions) RunProvidersSchema(binary string) (*dagger.Container, container.Runtime, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersSchema(tg.td, tg.TfGlobalOptions, []string{})
+}
+
+func (tg *TerragruntRunnerOptions) RunProvidersSchemaE(binary string) (*ProvidersSchema, error) {
+	tfIaac := IasC{
+		Config: getIaacConfigByBinary(binary),
+	}
+
+	return tfIaac.ProvidersSchemaE(tg.td, tg.TfGlobalOptions, []string{})
+}
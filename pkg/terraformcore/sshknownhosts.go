@@ -0,0 +1,41 @@
+package terraformcore
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// getSSHKnownHosts returns the content of the known_hosts file of the container: the entries of the
+// known hosts file of the host, and the inline entries. In strict mode, at least one entry is required.
+func getSSHKnownHosts(tfOpts TfGlobalOptions) (string, error) {
+	var entries []string
+
+	if knownHostsFile := tfOpts.GetSSHKnownHostsFile(); knownHostsFile != "" {
+		content, err := os.ReadFile(knownHostsFile)
+		if err != nil {
+			return "", erroer.NewErrTerraformCoreInvalidConfigurationError(
+				fmt.Sprintf("unable to read the known hosts file %s", knownHostsFile), err)
+		}
+
+		entries = append(entries, strings.Split(string(content), "\n")...)
+	}
+
+	entries = append(entries, tfOpts.GetSSHKnownHosts()...)
+
+	var knownHosts strings.Builder
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			knownHosts.WriteString(entry + "\n")
+		}
+	}
+
+	if tfOpts.IsSSHStrictHostKeyChecking() && knownHosts.Len() == 0 {
+		return "", erroer.NewErrTerraformCoreInvalidConfigurationError(
+			"the strict host key checking requires a known hosts file, or known hosts entries", nil)
+	}
+
+	return knownHosts.String(), nil
+}
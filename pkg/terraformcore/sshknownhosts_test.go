@@ -0,0 +1,31 @@
+package terraformcore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSSHKnownHosts(t *testing.T) {
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	assert.NoError(t, os.WriteFile(knownHostsFile, []byte("github.com ssh-ed25519 AAAA1\n\n"), 0o600))
+
+	knownHosts, err := getSSHKnownHosts(WithOptions(nil, &TfOptions{
+		SSHKnownHostsFile: knownHostsFile,
+		SSHKnownHosts:     []string{"gitlab.com ssh-ed25519 AAAA2"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com ssh-ed25519 AAAA1\ngitlab.com ssh-ed25519 AAAA2\n", knownHosts)
+
+	knownHosts, err = getSSHKnownHosts(WithOptions(nil, &TfOptions{}))
+	assert.NoError(t, err)
+	assert.Empty(t, knownHosts)
+
+	_, err = getSSHKnownHosts(WithOptions(nil, &TfOptions{SSHStrictHostKeyChecking: true}))
+	assert.Error(t, err)
+
+	_, err = getSSHKnownHosts(WithOptions(nil, &TfOptions{SSHKnownHostsFile: filepath.Join(t.TempDir(), "missing")}))
+	assert.Error(t, err)
+}
@@ -17,8 +17,13 @@ type ApplyArgsOptions struct {
 	TerraformVarFiles []string
 	// Vars is a list of terraform vars to use
 	Vars []TFInputVariable
+	// JSON is a flag to output the machine-readable UI (terraform apply -json), which can be
+	// parsed with ParseUIEvents or NewUIEventsStepLog
+	JSON bool
 	// AutoApprove is a flag to auto approve the plan
 	AutoApprove bool
+	// Guardrails, if set, inspects the plan and refuses destructive changes before running the command
+	Guardrails *Guardrails
 
 	// TfGlobalOptions is a struct that contains the global options for the terraform binary
 	// It implements the TfGlobalOptions interface
@@ -32,8 +37,11 @@ type ApplyArgs interface {
 	GetArgTerraformVarFilesValue() []string
 	GetArgVars() []string
 	GetArgVarsValue() []TFInputVariable
+	GetArgJSON() []string
+	GetArgJSONValue() bool
 	GetArgAutoApprove() []string
 	GetArgAutoApproveValue() bool
+	GetGuardrails() *Guardrails
 
 	// ApplyArgsValidator is an interface for validating the apply args,
 	// And also inherits from the TfArgs interface
@@ -91,6 +99,21 @@ func (po *ApplyArgsOptions) GetArgAutoApproveValue() bool {
 	return po.AutoApprove
 }
 
+func (po *ApplyArgsOptions) GetGuardrails() *Guardrails {
+	return po.Guardrails
+}
+
+func (po *ApplyArgsOptions) GetArgJSON() []string {
+	if po.JSON {
+		return []string{"-json"}
+	}
+	return []string{}
+}
+
+func (po *ApplyArgsOptions) GetArgJSONValue() bool {
+	return po.JSON
+}
+
 func (po *ApplyArgsOptions) VarFilesAreValid() error {
 	varFiles := po.GetArgTerraformVarFilesValue()
 
@@ -109,5 +132,20 @@ func (po *ApplyArgsOptions) AreValid() error {
 		return erroer.NewErrTerraformCoreInvalidArgumentError("the var files are not valid", err)
 	}
 
+	if po.JSON && !po.AutoApprove {
+		return erroer.NewErrTerraformCoreInvalidArgumentError("the JSON output requires auto approve to be enabled", nil)
+	}
+
+	if po.Guardrails != nil {
+		// The inspected plan is applied as a saved plan, which terraform never asks to approve.
+		if !po.AutoApprove {
+			return erroer.NewErrTerraformCoreInvalidArgumentError("the guardrails require auto approve to be enabled", nil)
+		}
+
+		if err := po.Guardrails.AreValid(); err != nil {
+			return erroer.NewErrTerraformCoreInvalidArgumentError("the guardrails are not valid", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,93 @@
+package terraformcore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// providersMirrorContainerDir is where the filesystem mirror of the host is mounted in the container.
+const providersMirrorContainerDir = "/terradagger/providers-mirror"
+
+// ProviderMirror configures where terraform installs the providers from, through a CLI configuration
+// (.terraformrc) generated by terradagger. Without DirectFallback, the providers are only installed
+// from the mirrors, which allows air-gapped runs.
+type ProviderMirror struct {
+	// FilesystemMirrorDir is a directory of the host with the providers (e.g. created by ProvidersMirrorE).
+	// It's mounted into the container.
+	FilesystemMirrorDir string
+	// NetworkMirrorURL is the URL of a provider network mirror, e.g. https://mirror.example.com/providers/
+	NetworkMirrorURL string
+	// DirectFallback installs the providers that aren't in the mirrors from their origin registries.
+	DirectFallback bool
+}
+
+// AreValid checks that there's at least one mirror, and that the filesystem mirror exists.
+func (m *ProviderMirror) AreValid() error {
+	if m.FilesystemMirrorDir == "" && m.NetworkMirrorURL == "" {
+		return erroer.NewErrTerraformCoreInvalidConfigurationError("the provider mirror requires a filesystem mirror directory, or a network mirror URL", nil)
+	}
+
+	if m.NetworkMirrorURL != "" && !strings.HasPrefix(m.NetworkMirrorURL, "https://") {
+		return erroer.NewErrTerraformCoreInvalidConfigurationError(fmt.Sprintf("the network mirror URL %s must use https", m.NetworkMirrorURL), nil)
+	}
+
+	if m.FilesystemMirrorDir != "" {
+		mount := m.getMount()
+		if err := mount.Validate(); err != nil {
+			return erroer.NewErrTerraformCoreInvalidConfigurationError("the filesystem mirror directory doesn't exist", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderMirror) getMount() container.Mount {
+	return container.Mount{
+		HostPath:      m.FilesystemMirrorDir,
+		ContainerPath: providersMirrorContainerDir,
+	}
+}
+
+// GetCLIConfig returns the terraform CLI configuration, with the provider_installation block.
+func (m *ProviderMirror) GetCLIConfig() string {
+	var cliConfig strings.Builder
+	cliConfig.WriteString("provider_installation {\n")
+
+	if m.FilesystemMirrorDir != "" {
+		cliConfig.WriteString(fmt.Sprintf("  filesystem_mirror {\n    path = %q\n  }\n", providersMirrorContainerDir))
+	}
+
+	if m.NetworkMirrorURL != "" {
+		cliConfig.WriteString(fmt.Sprintf("  network_mirror {\n    url = %q\n  }\n", m.NetworkMirrorURL))
+	}
+
+	if m.DirectFallback {
+		cliConfig.WriteString("  direct {}\n")
+	}
+
+	cliConfig.WriteString("}\n")
+
+	return cliConfig.String()
+}
+
+// getProviderMirrorConfig returns the CLI configuration, and the mounts, of the provider mirror of the options.
+func getProviderMirrorConfig(tfOpts TfGlobalOptions) (string, []container.Mount, error) {
+	mirror := tfOpts.GetProviderMirror()
+	if mirror == nil {
+		return "", nil, nil
+	}
+
+	if err := mirror.AreValid(); err != nil {
+		return "", nil, err
+	}
+
+	var mounts []container.Mount
+	if mirror.FilesystemMirrorDir != "" {
+		mounts = append(mounts, mirror.getMount())
+	}
+
+	return mirror.GetCLIConfig(), mounts, nil
+}
@@ -0,0 +1,50 @@
+package terraformcore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderMirror_GetCLIConfig(t *testing.T) {
+	mirror := &ProviderMirror{
+		FilesystemMirrorDir: t.TempDir(),
+		NetworkMirrorURL:    "https://mirror.example.com/providers/",
+		DirectFallback:      true,
+	}
+	assert.NoError(t, mirror.AreValid())
+
+	assert.Equal(t, `provider_installation {
+  filesystem_mirror {
+    path = "/terradagger/providers-mirror"
+  }
+  network_mirror {
+    url = "https://mirror.example.com/providers/"
+  }
+  direct {}
+}
+`, mirror.GetCLIConfig())
+
+	cliConfig, mounts, err := getProviderMirrorConfig(WithOptions(nil, &TfOptions{ProviderMirror: mirror}))
+	assert.NoError(t, err)
+	assert.Equal(t, mirror.GetCLIConfig(), cliConfig)
+	assert.Len(t, mounts, 1)
+	assert.Equal(t, "/terradagger/providers-mirror", mounts[0].ContainerPath)
+
+	cliConfig, mounts, err = getProviderMirrorConfig(WithOptions(nil, &TfOptions{}))
+	assert.NoError(t, err)
+	assert.Empty(t, cliConfig)
+	assert.Empty(t, mounts)
+}
+
+func TestProviderMirror_AreValid(t *testing.T) {
+	assert.Error(t, (&ProviderMirror{}).AreValid())
+	assert.Error(t, (&ProviderMirror{NetworkMirrorURL: "http://mirror.example.com/"}).AreValid())
+	assert.Error(t, (&ProviderMirror{FilesystemMirrorDir: "/does/not/exist"}).AreValid())
+}
+
+func TestProvidersMirrorArgsOptions(t *testing.T) {
+	args := &ProvidersMirrorArgsOptions{Platforms: []string{"linux_amd64", "darwin_arm64"}}
+	assert.Error(t, args.AreValid())
+	assert.Equal(t, []string{"-platform=linux_amd64", "-platform=darwin_arm64"}, args.GetArgPlatforms())
+}
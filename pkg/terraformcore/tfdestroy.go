@@ -28,7 +28,7 @@ func (i *IasC) Destroy(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs Des
 
 	var args []string
 	if tfCmdArgs != nil {
-		args = utils.MergeSlices(tfCmdArgs.GetArgVars(), tfCmdArgs.GetArgTerraformVarFiles(), tfCmdArgs.GetArgRefreshOnly(), tfCmdArgs.GetArgAutoApprove())
+		args = utils.MergeSlices(tfCmdArgs.GetArgVars(), tfCmdArgs.GetArgTerraformVarFiles(), tfCmdArgs.GetArgRefreshOnly(), tfCmdArgs.GetArgAutoApprove(), tfCmdArgs.GetArgJSON())
 	}
 
 	if i.Config.GetBinary() == config.IacToolTerraform {
@@ -56,19 +56,23 @@ func (i *IasC) Destroy(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs Des
 
 	tfInitCMDStr, tfCMDInitErr := tfLifeCycleCmd.GenerateTFInitCommandStr(&GenerateTFInitCMDStrOptions{
 		iacConfig: i.Config,
-		initArgs:  []string{},
+		initArgs:  getBackendConfigArgs(tfOpts.GetBackendConfig()),
 	})
 
 	if tfCMDInitErr != nil {
 		return nil, nil, tfCMDInitErr
 	}
 
-	tfCMDStrShell := terradagger.BuildCMDWithSH(tfCMDStr)
+	tfCMDStrShell := terradagger.BuildInterruptibleCMDWithSH(tfCMDStr, td.GetInterruptAt(), td.GetInterruptMarkerFile())
 	tfCMDInitStrSHell := terradagger.BuildCMDWithSH(tfInitCMDStr)
 
 	td.Log.Info(fmt.Sprintf("running %s with the following command: %s", i.Config.GetBinary(), tfCMDStr))
 
-	runtime := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	tfContainer := runtime.CreateContainer()
 	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
 
@@ -76,6 +80,18 @@ func (i *IasC) Destroy(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs Des
 	tfInitInjected := []container.Command{tfCMDInitStrSHell}
 
 	tfContainer = runtime.AddCommands(tfInitInjected, tfContainer)
+
+	// With guardrails, the plan is inspected first and the saved plan is applied instead.
+	if guardrails := tfCmdArgs.GetGuardrails(); guardrails != nil {
+		planArgs := utils.MergeSlices([]string{"-destroy"}, tfCmdArgs.GetArgVars(), tfCmdArgs.GetArgTerraformVarFiles(), tfCmdArgs.GetArgRefreshOnly())
+		guardedContainer, err := i.applyWithGuardrails(td, runtime, tfContainer, guardrails, planArgs, utils.MergeSlices(tfCmdArgs.GetArgAutoApprove(), tfCmdArgs.GetArgJSON()))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return guardedContainer, runtime, nil
+	}
+
 	tfContainer = runtime.AddCommands(tfCmds, tfContainer)
 
 	return tfContainer, runtime, nil
@@ -89,7 +105,7 @@ func (i *IasC) DestroyE(td *terradagger.TD, tfOpts TfGlobalOptions, options Dest
 
 	out, execErr := runtime.RunAndGetStdout(tfInitContainer)
 	if execErr != nil {
-		return "", err
+		return "", execErr
 	}
 
 	td.Log.Info(out)
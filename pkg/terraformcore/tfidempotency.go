@@ -0,0 +1,93 @@
+package terraformcore
+
+import (
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
+)
+
+const (
+	idempotencyPlanFile = "terradagger-idempotency.tfplan"
+	// planExitCodeChanges is the exit code of plan -detailed-exitcode when there are changes.
+	planExitCodeChanges = 2
+)
+
+// ApplyAndVerifyIdempotentE applies, and then runs plan -detailed-exitcode on the same container. If
+// the plan still shows changes, it returns an ErrTerraformCoreNotIdempotentError listing the resources.
+func (i *IasC) ApplyAndVerifyIdempotentE(td *terradagger.TD, tfOpts TfGlobalOptions, options ApplyArgs, extraArgs []string) (string, error) {
+	tfApplyContainer, runtime, err := i.Apply(td, tfOpts, options, extraArgs)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := runtime.RunAndGetStdout(tfApplyContainer)
+	if err != nil {
+		return "", err
+	}
+
+	td.Log.Info(out)
+
+	planArgs := utils.MergeSlices(options.GetArgVars(), options.GetArgTerraformVarFiles())
+	if err := i.VerifyIdempotent(td, runtime, tfApplyContainer, planArgs); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+// VerifyIdempotent runs plan -detailed-exitcode on a container where the module was already applied
+// (e.g. the one returned by Apply), and returns an ErrTerraformCoreNotIdempotentError if the plan
+// shows changes.
+func (i *IasC) VerifyIdempotent(td *terradagger.TD, runtime container.Runtime, tfContainer *dagger.Container, planArgs []string) error {
+	tfLifeCycleCmd := TfLifecycleCMD{}
+
+	planCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetPlanCommand(),
+		args:             utils.MergeSlices([]string{"-detailed-exitcode", "-input=false", "-no-color", fmt.Sprintf("-out=%s", idempotencyPlanFile)}, planArgs),
+	})
+	if err != nil {
+		return err
+	}
+
+	showCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetShowCommand(),
+		args:             []string{"-json", idempotencyPlanFile},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The plan output goes to stderr, so the stdout is either empty (no changes), or the JSON plan.
+	verifyCMDStr := fmt.Sprintf(`%s 1>&2; status=$?; if [ "$status" -eq %d ]; then %s; exit $?; fi; exit $status`,
+		planCMDStr, planExitCodeChanges, showCMDStr)
+
+	td.Log.Info(fmt.Sprintf("verifying that the apply is idempotent: %s", planCMDStr))
+
+	out, err := runtime.RunAndGetStdout(runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(verifyCMDStr)}, tfContainer))
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(out) == "" {
+		return nil
+	}
+
+	plan, err := ParsePlanJSON(out)
+	if err != nil {
+		return err
+	}
+
+	var resources []string
+	for _, rc := range plan.GetChanges() {
+		resources = append(resources, fmt.Sprintf("%s (%s)", rc.Address, strings.Join(rc.Change.Actions, ", ")))
+	}
+
+	return erroer.NewErrTerraformCoreNotIdempotentError(resources)
+}
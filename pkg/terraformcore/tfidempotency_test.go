@@ -0,0 +1,34 @@
+package terraformcore
+
+import (
+	"testing"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTfPlanJSON_GetChanges(t *testing.T) {
+	plan, err := ParsePlanJSON(`{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "random_string.this", "change": {"actions": ["update"]}},
+    {"address": "random_pet.this", "change": {"actions": ["no-op"]}},
+    {"address": "data.aws_caller_identity.current", "mode": "data", "change": {"actions": ["read"]}},
+    {"address": "aws_s3_bucket.this", "change": {"actions": ["delete", "create"]}}
+  ]
+}`)
+	assert.NoError(t, err)
+
+	changes := plan.GetChanges()
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "random_string.this", changes[0].Address)
+	assert.Equal(t, "aws_s3_bucket.this", changes[1].Address)
+}
+
+func TestNewErrTerraformCoreNotIdempotentError(t *testing.T) {
+	err := erroer.NewErrTerraformCoreNotIdempotentError([]string{"random_string.this (update)"})
+	assert.Contains(t, err.Error(), "random_string.this (update)")
+
+	err = erroer.NewErrTerraformCoreNotIdempotentError(nil)
+	assert.Contains(t, err.Error(), "only the outputs changed")
+}
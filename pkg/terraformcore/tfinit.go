@@ -29,8 +29,10 @@ func (i *IasC) Init(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs InitAr
 	}
 
 	var args []string
+	var backendConfig map[string]string
 	if tfCmdArgs != nil {
-		args = utils.MergeSlices(tfCmdArgs.GetArgUpgrade(), tfCmdArgs.GetArgNoColor(), tfCmdArgs.GetArgBackendConfigFile())
+		backendConfig = utils.MergeMaps(tfOpts.GetBackendConfig(), tfCmdArgs.GetArgBackendConfigValue())
+		args = utils.MergeSlices(tfCmdArgs.GetArgUpgrade(), tfCmdArgs.GetArgNoColor(), tfCmdArgs.GetArgBackendConfigFile(), getBackendConfigArgs(backendConfig))
 	}
 
 	if i.Config.GetBinary() == config.IacToolTerraform {
@@ -59,9 +61,15 @@ func (i *IasC) Init(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs InitAr
 	tfCommandShell := terradagger.BuildCMDWithSH(tfCMDStr)
 	td.Log.Info(fmt.Sprintf("running %s plan with the following command: %s", i.Config.GetBinary(), tfCMDStr))
 
-	runtime := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	tfContainer := runtime.CreateContainer()
 	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
+	// The backend config of the command overrides the secrets of the global options.
+	tfContainer = runtime.AddSecretEnvVars(getBackendConfigSecretEnvVars(backendConfig), tfContainer)
 
 	tfCmds := []container.Command{tfCommandShell}
 	tfContainer = runtime.AddCommands(tfCmds, tfContainer)
@@ -77,7 +85,7 @@ func (i *IasC) InitE(td *terradagger.TD, tfOpts TfGlobalOptions, options InitArg
 
 	out, execErr := runtime.RunAndGetStdout(tfInitContainer)
 	if execErr != nil {
-		return "", err
+		return "", execErr
 	}
 
 	td.Log.Info(out)
@@ -1,7 +1,10 @@
 package terraformcore
 
 import (
+	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Excoriate/go-terradagger/pkg/erroer"
 	"github.com/Excoriate/go-terradagger/pkg/utils"
@@ -14,6 +17,9 @@ type InitArgsOptions struct {
 	BackendConfigFile string
 	// Upgrade is a flag to upgrade the modules and plugins
 	Upgrade bool
+	// BackendConfig is the backend configuration, passed as -backend-config key=value. It's merged
+	// with (and overrides) the BackendConfig of the global options
+	BackendConfig map[string]string
 
 	// TfGlobalOptions is a struct that contains the global options for the terraform binary
 	// It implements the TfGlobalOptions interface
@@ -27,6 +33,8 @@ type InitArgs interface {
 	GetArgBackendConfigFileValue() string
 	GetArgUpgrade() []string
 	GetArgUpgradeValue() bool
+	GetArgBackendConfig() []string
+	GetArgBackendConfigValue() map[string]string
 
 	// InitArgsValidator is an interface for validating the init args,
 	// And also inherits from the TfArgs interface
@@ -77,6 +85,81 @@ func (ti *InitArgsOptions) GetArgUpgradeValue() bool {
 	return ti.Upgrade
 }
 
+func (ti *InitArgsOptions) GetArgBackendConfig() []string {
+	return getBackendConfigArgs(ti.BackendConfig)
+}
+
+func (ti *InitArgsOptions) GetArgBackendConfigValue() map[string]string {
+	return ti.BackendConfig
+}
+
+// sensitiveBackendConfigKeys are the backend configuration keys whose values are secrets: they're passed
+// to the container as secret env vars, instead of in the command.
+var sensitiveBackendConfigKeys = []string{"access_key", "secret_key", "token", "password", "client_secret", "sas_token"}
+
+// backendConfigSecretEnvVarPrefix prefixes the secret env vars of the sensitive backend configuration.
+const backendConfigSecretEnvVarPrefix = "TERRADAGGER_BACKEND_CONFIG_"
+
+func isSensitiveBackendConfigKey(key string) bool {
+	for _, sensitive := range sensitiveBackendConfigKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getBackendConfigSecretEnvVar returns the env var that holds the value of a sensitive backend
+// configuration key, e.g. TERRADAGGER_BACKEND_CONFIG_SECRET_KEY of secret_key.
+func getBackendConfigSecretEnvVar(key string) string {
+	return backendConfigSecretEnvVarPrefix + strings.ToUpper(key)
+}
+
+// getBackendConfigArgs returns the -backend-config arguments, sorted by key. Each key=value is single
+// quoted for the shell. The values of the sensitive keys (e.g. secret_key) aren't in the arguments:
+// they're read from their secret env var (see getBackendConfigSecretEnvVars), so they're neither in the
+// Dagger logs nor in the cache keys.
+func getBackendConfigArgs(backendConfig map[string]string) []string {
+	keys := make([]string, 0, len(backendConfig))
+	for key := range backendConfig {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var args []string
+	for _, key := range keys {
+		if isSensitiveBackendConfigKey(key) {
+			args = append(args, fmt.Sprintf(`-backend-config="%s=${%s}"`, key, getBackendConfigSecretEnvVar(key)))
+			continue
+		}
+
+		args = append(args, fmt.Sprintf("-backend-config=%s", quoteShellSingle(fmt.Sprintf("%s=%s", key, backendConfig[key]))))
+	}
+
+	return args
+}
+
+// getBackendConfigSecretEnvVars returns the secret env vars with the values of the sensitive backend
+// configuration keys, read by the -backend-config arguments.
+func getBackendConfigSecretEnvVars(backendConfig map[string]string) map[string]string {
+	envVars := map[string]string{}
+	for key, value := range backendConfig {
+		if isSensitiveBackendConfigKey(key) {
+			envVars[getBackendConfigSecretEnvVar(key)] = value
+		}
+	}
+
+	return envVars
+}
+
+// quoteShellSingle single quotes the value for a POSIX shell. A single quote can't be escaped inside
+// single quotes, so it closes the quotes, adds an escaped quote, and opens them again.
+func quoteShellSingle(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
 func (ti *InitArgsOptions) BackendFileIsValid() error {
 	beCfgFile := ti.GetArgBackendConfigFileValue()
 
@@ -0,0 +1,66 @@
+package terraformcore
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBackendConfigArgs(t *testing.T) {
+	args := getBackendConfigArgs(map[string]string{
+		"key":    "it's/terraform.tfstate",
+		"bucket": "states",
+	})
+
+	assert.Equal(t, []string{
+		"-backend-config='bucket=states'",
+		`-backend-config='key=it'\''s/terraform.tfstate'`,
+	}, args)
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// The shell must see the values as they are.
+	out, err := exec.Command("sh", "-c", "printf '%s\\n' "+strings.Join(args, " ")).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "-backend-config=bucket=states\n-backend-config=key=it's/terraform.tfstate\n", string(out))
+}
+
+func TestGetBackendConfigArgs_Sensitive(t *testing.T) {
+	backendConfig := map[string]string{
+		"access_key": "AKIA",
+		"secret_key": "s3cr3t'x",
+		"bucket":     "states",
+	}
+
+	args := getBackendConfigArgs(backendConfig)
+	assert.Equal(t, []string{
+		`-backend-config="access_key=${TERRADAGGER_BACKEND_CONFIG_ACCESS_KEY}"`,
+		"-backend-config='bucket=states'",
+		`-backend-config="secret_key=${TERRADAGGER_BACKEND_CONFIG_SECRET_KEY}"`,
+	}, args)
+	assert.NotContains(t, strings.Join(args, " "), "s3cr3t")
+
+	envVars := getBackendConfigSecretEnvVars(backendConfig)
+	assert.Equal(t, map[string]string{
+		"TERRADAGGER_BACKEND_CONFIG_ACCESS_KEY": "AKIA",
+		"TERRADAGGER_BACKEND_CONFIG_SECRET_KEY": "s3cr3t'x",
+	}, envVars)
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// The shell reads the values from the env vars, as they are.
+	cmd := exec.Command("sh", "-c", "printf '%s\\n' "+strings.Join(args, " "))
+	for name, value := range envVars {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "-backend-config=access_key=AKIA\n-backend-config=bucket=states\n-backend-config=secret_key=s3cr3t'x\n", string(out))
+}
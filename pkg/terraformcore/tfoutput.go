@@ -0,0 +1,50 @@
+package terraformcore
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// TfOutput is an output of the machine-readable terraform output -json.
+type TfOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// GetValueString returns the value of the output: strings are unquoted, and the other types are
+// returned as JSON.
+func (o *TfOutput) GetValueString() string {
+	var value string
+	if err := json.Unmarshal(o.Value, &value); err == nil {
+		return value
+	}
+
+	return string(o.Value)
+}
+
+// DecodeValue decodes the value of the output into v (e.g. a map, or a list).
+func (o *TfOutput) DecodeValue(v any) error {
+	if err := json.Unmarshal(o.Value, v); err != nil {
+		return erroer.NewErrTerraformCoreInvalidArgumentError("the output value cannot be decoded", err)
+	}
+
+	return nil
+}
+
+// ParseOutputsJSON parses the output of terraform output -json.
+func ParseOutputsJSON(out string) (map[string]TfOutput, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the outputs are empty", nil)
+	}
+
+	outputs := map[string]TfOutput{}
+	if err := json.Unmarshal([]byte(out), &outputs); err != nil {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the outputs are not valid JSON", err)
+	}
+
+	return outputs, nil
+}
@@ -0,0 +1,30 @@
+package terraformcore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputsJSON(t *testing.T) {
+	outputs, err := ParseOutputsJSON(`{
+  "name": {"sensitive": false, "type": "string", "value": "random-abc"},
+  "tags": {"sensitive": true, "type": ["map", "string"], "value": {"env": "dev"}}
+}`)
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 2)
+
+	name := outputs["name"]
+	assert.Equal(t, "random-abc", name.GetValueString())
+
+	tags := outputs["tags"]
+	assert.True(t, tags.Sensitive)
+	assert.Equal(t, `{"env": "dev"}`, tags.GetValueString())
+
+	var decoded map[string]string
+	assert.NoError(t, tags.DecodeValue(&decoded))
+	assert.Equal(t, "dev", decoded["env"])
+
+	_, err = ParseOutputsJSON("")
+	assert.Error(t, err)
+}
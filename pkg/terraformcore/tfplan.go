@@ -27,7 +27,7 @@ func (i *IasC) Plan(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs PlanAr
 
 	var args []string
 	if tfCmdArgs != nil {
-		args = utils.MergeSlices(tfCmdArgs.GetArgVars(), tfCmdArgs.GetArgTerraformVarFiles(), tfCmdArgs.GetArgRefreshOnly())
+		args = utils.MergeSlices(tfCmdArgs.GetArgVars(), tfCmdArgs.GetArgTerraformVarFiles(), tfCmdArgs.GetArgRefreshOnly(), tfCmdArgs.GetArgOut(), tfCmdArgs.GetArgJSON())
 	}
 
 	if i.Config.GetBinary() == config.IacToolTerraform {
@@ -55,7 +55,7 @@ func (i *IasC) Plan(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs PlanAr
 
 	tfInitCMDStr, tfCMDInitErr := tfLifeCycleCmd.GenerateTFInitCommandStr(&GenerateTFInitCMDStrOptions{
 		iacConfig: i.Config,
-		initArgs:  []string{},
+		initArgs:  getBackendConfigArgs(tfOpts.GetBackendConfig()),
 	})
 
 	if tfCMDInitErr != nil {
@@ -69,7 +69,11 @@ func (i *IasC) Plan(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs PlanAr
 
 	td.Log.Info(fmt.Sprintf("running %s plan with the following command: %s", i.Config.GetBinary(), tfCMDStr))
 
-	runtime := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	tfContainer := runtime.CreateContainer()
 	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
 
@@ -87,7 +91,7 @@ func (i *IasC) PlanE(td *terradagger.TD, tfOpts TfGlobalOptions, options PlanArg
 
 	out, execErr := runtime.RunAndGetStdout(tfInitContainer)
 	if execErr != nil {
-		return "", err
+		return "", execErr
 	}
 
 	td.Log.Info(out)
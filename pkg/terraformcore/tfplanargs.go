@@ -17,6 +17,12 @@ type PlanArgsOptions struct {
 	TerraformVarFiles []string
 	// Vars is a list of terraform vars to use
 	Vars []TFInputVariable
+	// JSON is a flag to output the machine-readable UI (terraform plan -json), which can be
+	// parsed with ParseUIEvents or NewUIEventsStepLog
+	JSON bool
+	// OutFile is the path (relative to the module) where the plan is saved. Equivalent to
+	// terraform plan -out
+	OutFile string
 
 	// TfGlobalOptions is a struct that contains the global options for the terraform binary
 	// It implements the TfGlobalOptions interface
@@ -30,6 +36,10 @@ type PlanArgs interface {
 	GetArgTerraformVarFilesValue() []string
 	GetArgVars() []string
 	GetArgVarsValue() []TFInputVariable
+	GetArgJSON() []string
+	GetArgJSONValue() bool
+	GetArgOut() []string
+	GetArgOutValue() string
 
 	// PlanArgsValidator is an interface for validating the plan args,
 	// And also inherits from the TfArgs interface
@@ -76,6 +86,28 @@ func (po *PlanArgsOptions) GetArgVarsValue() []TFInputVariable {
 	return po.Vars
 }
 
+func (po *PlanArgsOptions) GetArgOut() []string {
+	if po.OutFile != "" {
+		return []string{fmt.Sprintf("-out=%s", po.OutFile)}
+	}
+	return []string{}
+}
+
+func (po *PlanArgsOptions) GetArgOutValue() string {
+	return po.OutFile
+}
+
+func (po *PlanArgsOptions) GetArgJSON() []string {
+	if po.JSON {
+		return []string{"-json"}
+	}
+	return []string{}
+}
+
+func (po *PlanArgsOptions) GetArgJSONValue() bool {
+	return po.JSON
+}
+
 func (po *PlanArgsOptions) VarFilesAreValid() error {
 	varFiles := po.GetArgTerraformVarFilesValue()
 
@@ -0,0 +1,108 @@
+package terraformcore
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+const (
+	tfActionNoOp   = "no-op"
+	tfActionCreate = "create"
+	tfActionRead   = "read"
+	tfActionUpdate = "update"
+	tfActionDelete = "delete"
+)
+
+// TfPlanJSON is the subset of the machine-readable plan (terraform show -json <planfile>)
+// that terradagger relies on.
+type TfPlanJSON struct {
+	FormatVersion    string             `json:"format_version"`
+	TerraformVersion string             `json:"terraform_version"`
+	ResourceChanges  []TfResourceChange `json:"resource_changes"`
+}
+
+type TfResourceChange struct {
+	Address       string   `json:"address"`
+	ModuleAddress string   `json:"module_address,omitempty"`
+	Mode          string   `json:"mode"`
+	Type          string   `json:"type"`
+	Name          string   `json:"name"`
+	ProviderName  string   `json:"provider_name"`
+	Change        TfChange `json:"change"`
+}
+
+type TfChange struct {
+	Actions []string `json:"actions"`
+}
+
+// ParsePlanJSON parses the output of terraform show -json <planfile>.
+func ParsePlanJSON(out string) (*TfPlanJSON, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the plan output is empty", nil)
+	}
+
+	var plan TfPlanJSON
+	if err := json.Unmarshal([]byte(out), &plan); err != nil {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the plan output is not a valid JSON plan", err)
+	}
+
+	return &plan, nil
+}
+
+func (rc *TfResourceChange) hasAction(action string) bool {
+	for _, a := range rc.Change.Actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsDelete returns true if the resource is going to be deleted, including replacements.
+func (rc *TfResourceChange) IsDelete() bool {
+	return rc.hasAction(tfActionDelete)
+}
+
+// IsReplace returns true if the resource is going to be destroyed and re-created.
+func (rc *TfResourceChange) IsReplace() bool {
+	return rc.hasAction(tfActionDelete) && rc.hasAction(tfActionCreate)
+}
+
+// IsNoOp returns true if the resource has no pending changes.
+func (rc *TfResourceChange) IsNoOp() bool {
+	return len(rc.Change.Actions) == 0 || (len(rc.Change.Actions) == 1 && rc.hasAction(tfActionNoOp))
+}
+
+// GetDeletions returns the resource changes that delete (or replace) a resource.
+func (p *TfPlanJSON) GetDeletions() []TfResourceChange {
+	var deletions []TfResourceChange
+	for _, rc := range p.ResourceChanges {
+		if rc.IsDelete() {
+			deletions = append(deletions, rc)
+		}
+	}
+
+	return deletions
+}
+
+// IsRead returns true if the data source is going to be read.
+func (rc *TfResourceChange) IsRead() bool {
+	return len(rc.Change.Actions) == 1 && rc.hasAction(tfActionRead)
+}
+
+// GetChanges returns the resource changes that create, update or delete a resource; the no-op ones
+// and the data sources that are only read are excluded.
+func (p *TfPlanJSON) GetChanges() []TfResourceChange {
+	var changes []TfResourceChange
+	for _, rc := range p.ResourceChanges {
+		if !rc.IsNoOp() && !rc.IsRead() {
+			changes = append(changes, rc)
+		}
+	}
+
+	return changes
+}
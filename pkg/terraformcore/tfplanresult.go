@@ -0,0 +1,70 @@
+package terraformcore
+
+import (
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+// DefaultPlanOutFile is the file where the plan is saved when building a plan result.
+const DefaultPlanOutFile = "terradagger.tfplan"
+
+// PlanResult is the structured result of a plan, built from the saved plan file.
+type PlanResult struct {
+	// Plan is the machine-readable plan (terraform show -json)
+	Plan *TfPlanJSON
+	// Output is the human-readable plan (terraform show -no-color)
+	Output string
+}
+
+// PlanResultE runs a plan that's saved into the container, and returns both the machine-readable and the
+// human-readable representations of it. The plan arguments must set an out file.
+func (i *IasC) PlanResultE(td *terradagger.TD, tfOpts TfGlobalOptions, options PlanArgs, extraArgs []string) (*PlanResult, error) {
+	if options.GetArgOutValue() == "" {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("an out file is required to build the plan result", nil)
+	}
+
+	tfPlanContainer, runtime, err := i.Plan(td, tfOpts, options, extraArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	tfLifeCycleCmd := TfLifecycleCMD{}
+	showJSONCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetShowCommand(),
+		args:             []string{"-json", options.GetArgOutValue()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	showCMDStr, err := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetShowCommand(),
+		args:             []string{"-no-color", options.GetArgOutValue()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	showJSONOut, err := runtime.RunAndGetStdout(runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(showJSONCMDStr)}, tfPlanContainer))
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := ParsePlanJSON(showJSONOut)
+	if err != nil {
+		return nil, err
+	}
+
+	showOut, err := runtime.RunAndGetStdout(runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(showCMDStr)}, tfPlanContainer))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlanResult{
+		Plan:   plan,
+		Output: showOut,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package terraformcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/config"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+)
+
+// tfLockFile is the dependency lock file, relative to the module.
+const tfLockFile = ".terraform.lock.hcl"
+
+// ProvidersSchema is the output of terraform providers schema -json. The schemas are kept as raw
+// JSON, keyed by provider source, e.g. registry.terraform.io/hashicorp/aws
+type ProvidersSchema struct {
+	FormatVersion   string                     `json:"format_version"`
+	ProviderSchemas map[string]json.RawMessage `json:"provider_schemas"`
+}
+
+// GetProviders returns the sources of the providers, sorted.
+func (s *ProvidersSchema) GetProviders() []string {
+	providers := make([]string, 0, len(s.ProviderSchemas))
+	for provider := range s.ProviderSchemas {
+		providers = append(providers, provider)
+	}
+
+	sort.Strings(providers)
+
+	return providers
+}
+
+// ParseProvidersSchemaJSON parses the output of terraform providers schema -json.
+func ParseProvidersSchemaJSON(out string) (*ProvidersSchema, error) {
+	var schema ProvidersSchema
+	if err := json.Unmarshal([]byte(out), &schema); err != nil {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("unable to parse the providers schema", err)
+	}
+
+	return &schema, nil
+}
+
+// isModuleValid checks that the module path exists, and that it has code for the binary.
+func (i *IasC) isModuleValid(tfOpts TfGlobalOptions) error {
+	if err := tfOpts.IsModulePathValid(); err != nil {
+		return err
+	}
+
+	if i.Config.GetBinary() == config.IacToolTerragrunt {
+		return tfOpts.ModulePathHasTerragruntHCL()
+	}
+
+	return tfOpts.ModulePathHasTerraformCode()
+}
+
+// ProvidersLock runs terraform providers lock on the module, which records the checksums of the
+// providers for each platform in the lock file. ProvidersLockE exports the lock file to the host.
+func (i *IasC) ProvidersLock(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs ProvidersLockArgs, _ []string) (*dagger.Container, container.Runtime, error) {
+	if err := i.isModuleValid(tfOpts); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tfCmdArgs.AreValid(); err != nil {
+		return nil, nil, err
+	}
+
+	tfLifeCycleCmd := TfLifecycleCMD{}
+	tfContainerCfg := &TerraformContainerConfigOptions{
+		tfOptions: tfOpts,
+		iacConfig: i.Config,
+		// It only downloads the providers, without the backend.
+		withoutServices: true,
+	}
+
+	tfCMDStr, tfCMDStrErr := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetProvidersLockCommand(),
+		args:             getPlatformArgsOrDefault(tfCmdArgs.GetArgPlatformsValue(), tfOpts.GetPlatform()),
+	})
+
+	if tfCMDStrErr != nil {
+		return nil, nil, tfCMDStrErr
+	}
+
+	td.Log.Info(fmt.Sprintf("running %s providers lock with the following command: %s", i.Config.GetBinary(), tfCMDStr))
+
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tfContainer := runtime.CreateContainer()
+	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
+	tfContainer = runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(tfCMDStr)}, tfContainer)
+
+	return tfContainer, runtime, nil
+}
+
+// ProvidersLockE runs terraform providers lock on the module, and exports the updated
+// .terraform.lock.hcl to the module directory of the host.
+func (i *IasC) ProvidersLockE(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersLockArgs, extraArgs []string) (string, error) {
+	tfContainer, runtime, err := i.ProvidersLock(td, tfOpts, options, extraArgs)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := runtime.RunAndGetStdout(tfContainer)
+	if err != nil {
+		return "", err
+	}
+
+	lockFile := filepath.Join(tfOpts.GetModulePathFull(), tfLockFile)
+	if _, err := tfContainer.File(tfLockFile).Export(td.Ctx, lockFile); err != nil {
+		return "", err
+	}
+
+	td.Log.Info(fmt.Sprintf("lock file exported to %s", lockFile))
+
+	return out, nil
+}
+
+// ProvidersSchema runs terraform init and terraform providers schema -json on the module.
+func (i *IasC) ProvidersSchema(td *terradagger.TD, tfOpts TfGlobalOptions, _ []string) (*dagger.Container, container.Runtime, error) {
+	if err := i.isModuleValid(tfOpts); err != nil {
+		return nil, nil, err
+	}
+
+	tfLifeCycleCmd := TfLifecycleCMD{}
+	tfContainerCfg := &TerraformContainerConfigOptions{
+		tfOptions: tfOpts,
+		iacConfig: i.Config,
+	}
+
+	tfCMDStr, tfCMDStrErr := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetProvidersSchemaCommand(),
+		args:             []string{"-json"},
+	})
+
+	if tfCMDStrErr != nil {
+		return nil, nil, tfCMDStrErr
+	}
+
+	tfInitCMDStr, tfCMDInitErr := tfLifeCycleCmd.GenerateTFInitCommandStr(&GenerateTFInitCMDStrOptions{
+		iacConfig: i.Config,
+		initArgs:  getBackendConfigArgs(tfOpts.GetBackendConfig()),
+	})
+
+	if tfCMDInitErr != nil {
+		return nil, nil, tfCMDInitErr
+	}
+
+	td.Log.Info(fmt.Sprintf("running %s providers schema with the following command: %s", i.Config.GetBinary(), tfCMDStr))
+
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tfContainer := runtime.CreateContainer()
+	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
+	tfContainer = runtime.AddCommands([]container.Command{
+		terradagger.BuildCMDWithSH(tfInitCMDStr),
+		terradagger.BuildCMDWithSH(tfCMDStr),
+	}, tfContainer)
+
+	return tfContainer, runtime, nil
+}
+
+// ProvidersSchemaE returns the schemas of the providers used by the module.
+func (i *IasC) ProvidersSchemaE(td *terradagger.TD, tfOpts TfGlobalOptions, extraArgs []string) (*ProvidersSchema, error) {
+	tfContainer, runtime, err := i.ProvidersSchema(td, tfOpts, extraArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runtime.RunAndGetStdout(tfContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseProvidersSchemaJSON(out)
+}
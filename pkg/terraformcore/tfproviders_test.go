@@ -0,0 +1,45 @@
+package terraformcore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvidersLockArgsOptions(t *testing.T) {
+	args := &ProvidersLockArgsOptions{Platforms: []string{"linux_amd64", "darwin_arm64"}}
+	assert.NoError(t, args.AreValid())
+	assert.Equal(t, []string{"-platform=linux_amd64", "-platform=darwin_arm64"}, args.GetArgPlatforms())
+
+	assert.NoError(t, (&ProvidersLockArgsOptions{}).AreValid())
+	assert.Empty(t, (&ProvidersLockArgsOptions{}).GetArgPlatforms())
+
+	for _, platform := range []string{"linux/amd64", "linux", "Linux_AMD64", "-platform=linux_amd64"} {
+		assert.Error(t, (&ProvidersLockArgsOptions{Platforms: []string{platform}}).AreValid(), platform)
+	}
+}
+
+func TestParseProvidersSchemaJSON(t *testing.T) {
+	schema, err := ParseProvidersSchemaJSON(`{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/random": {"provider": {"version": 0}},
+    "registry.terraform.io/hashicorp/aws": {"provider": {"version": 0}}
+  }
+}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", schema.FormatVersion)
+	assert.Equal(t, []string{
+		"registry.terraform.io/hashicorp/aws",
+		"registry.terraform.io/hashicorp/random",
+	}, schema.GetProviders())
+
+	_, err = ParseProvidersSchemaJSON("Initializing the backend...")
+	assert.Error(t, err)
+}
+
+func TestGetPlatformArgsOrDefault(t *testing.T) {
+	assert.Equal(t, []string{"-platform=linux_amd64"}, getPlatformArgsOrDefault(nil, "linux/amd64"))
+	assert.Equal(t, []string{"-platform=darwin_arm64"}, getPlatformArgsOrDefault([]string{"darwin_arm64"}, "linux/amd64"))
+	assert.Empty(t, getPlatformArgsOrDefault(nil, ""))
+}
@@ -0,0 +1,71 @@
+package terraformcore
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// platformRegex matches the platforms of terraform providers, e.g. linux_amd64 or darwin_arm64.
+var platformRegex = regexp.MustCompile(`^[a-z0-9]+_[a-z0-9]+$`)
+
+type ProvidersLockArgsOptions struct {
+	// Platforms are the platforms whose checksums are recorded in the lock file, e.g. linux_amd64 and
+	// darwin_arm64, so the lock file is valid on every machine that runs the module
+	Platforms []string
+
+	// TfGlobalOptions is a struct that contains the global options for the terraform binary
+	// It implements the TfGlobalOptions interface
+	TfGlobalOptions TfGlobalOptions
+}
+
+type ProvidersLockArgs interface {
+	GetArgPlatforms() []string
+	GetArgPlatformsValue() []string
+
+	// ProvidersLockArgs also inherits from the TfArgs interface
+	TfArgs
+}
+
+func (po *ProvidersLockArgsOptions) GetArgPlatforms() []string {
+	return getPlatformArgs(po.Platforms)
+}
+
+func (po *ProvidersLockArgsOptions) GetArgPlatformsValue() []string {
+	return po.Platforms
+}
+
+func (po *ProvidersLockArgsOptions) AreValid() error {
+	return arePlatformsValid(po.Platforms)
+}
+
+// getPlatformArgs returns the -platform arguments of the providers commands.
+func getPlatformArgs(platforms []string) []string {
+	var args []string
+	for _, platform := range platforms {
+		args = append(args, fmt.Sprintf("-platform=%s", platform))
+	}
+	return args
+}
+
+// getPlatformArgsOrDefault returns the -platform arguments of the providers commands. Without
+// platforms, the platform of the container (e.g. linux/amd64) is used, if it's set.
+func getPlatformArgsOrDefault(platforms []string, containerPlatform string) []string {
+	if len(platforms) == 0 && containerPlatform != "" {
+		return getPlatformArgs([]string{container.GetProviderPlatform(containerPlatform)})
+	}
+
+	return getPlatformArgs(platforms)
+}
+
+func arePlatformsValid(platforms []string) error {
+	for _, platform := range platforms {
+		if !platformRegex.MatchString(platform) {
+			return erroer.NewErrTerraformCoreInvalidArgumentError(fmt.Sprintf("the platform %s is not valid, it should be like linux_amd64", platform), nil)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package terraformcore
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"dagger.io/dagger"
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/terradagger"
+	"github.com/Excoriate/go-terradagger/pkg/utils"
+)
+
+// providersMirrorOutputDir is the directory of the container where terraform providers mirror saves the providers.
+const providersMirrorOutputDir = "/terradagger/providers-mirror-output"
+
+// ProvidersMirror runs terraform providers mirror on the module, which downloads the providers it
+// requires into a directory of the container. ProvidersMirrorE exports it to the host.
+func (i *IasC) ProvidersMirror(td *terradagger.TD, tfOpts TfGlobalOptions, tfCmdArgs ProvidersMirrorArgs, _ []string) (*dagger.Container, container.Runtime, error) {
+	if err := i.isModuleValid(tfOpts); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tfCmdArgs.AreValid(); err != nil {
+		return nil, nil, err
+	}
+
+	tfLifeCycleCmd := TfLifecycleCMD{}
+	tfContainerCfg := &TerraformContainerConfigOptions{
+		tfOptions: tfOpts,
+		iacConfig: i.Config,
+		// It only downloads the providers, without the backend.
+		withoutServices: true,
+	}
+
+	tfCMDStr, tfCMDStrErr := tfLifeCycleCmd.GetTerraformLifecycleCMDString(&GetTerraformLifecycleCMDStringOptions{
+		iacConfig:        i.Config,
+		lifecycleCommand: tfLifeCycleCmd.GetProvidersMirrorCommand(),
+		args:             utils.MergeSlices(getPlatformArgsOrDefault(tfCmdArgs.GetArgPlatformsValue(), tfOpts.GetPlatform()), []string{providersMirrorOutputDir}),
+	})
+
+	if tfCMDStrErr != nil {
+		return nil, nil, tfCMDStrErr
+	}
+
+	td.Log.Info(fmt.Sprintf("running %s providers mirror with the following command: %s", i.Config.GetBinary(), tfCMDStr))
+
+	runtime, err := tfContainerCfg.getContainerRuntime(td, tfContainerCfg.getContainerImageCfg(td))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tfContainer := runtime.CreateContainer()
+	tfContainer = tfContainerCfg.AddEnvVarsToTerraformContainer(td, runtime, tfContainer)
+	tfContainer = runtime.AddCommands([]container.Command{terradagger.BuildCMDWithSH(tfCMDStr)}, tfContainer)
+
+	return tfContainer, runtime, nil
+}
+
+// ProvidersMirrorE runs terraform providers mirror on the module, and exports the providers to the
+// target directory of the host, which can be used as a ProviderMirror.FilesystemMirrorDir.
+func (i *IasC) ProvidersMirrorE(td *terradagger.TD, tfOpts TfGlobalOptions, options ProvidersMirrorArgs, extraArgs []string) (string, error) {
+	tfContainer, runtime, err := i.ProvidersMirror(td, tfOpts, options, extraArgs)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := runtime.RunAndGetStdout(tfContainer)
+	if err != nil {
+		return "", err
+	}
+
+	targetDir := options.GetArgTargetDirValue()
+	if !filepath.IsAbs(targetDir) {
+		targetDir = filepath.Join(td.Config.GetWorkspaceAbs(), targetDir)
+	}
+
+	if _, err := tfContainer.Directory(providersMirrorOutputDir).Export(td.Ctx, targetDir); err != nil {
+		return "", err
+	}
+
+	td.Log.Info(fmt.Sprintf("providers mirrored into %s", targetDir))
+
+	return out, nil
+}
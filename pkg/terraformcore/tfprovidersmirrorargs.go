@@ -0,0 +1,47 @@
+package terraformcore
+
+import (
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+type ProvidersMirrorArgsOptions struct {
+	// TargetDir is the directory of the host where the providers are saved. If it's relative, it's
+	// relative to the terradagger workspace
+	TargetDir string
+	// Platforms are the platforms of the providers to download, e.g. linux_amd64. Defaults to the
+	// platform of the container
+	Platforms []string
+
+	// TfGlobalOptions is a struct that contains the global options for the terraform binary
+	// It implements the TfGlobalOptions interface
+	TfGlobalOptions TfGlobalOptions
+}
+
+type ProvidersMirrorArgs interface {
+	GetArgPlatforms() []string
+	GetArgPlatformsValue() []string
+	GetArgTargetDirValue() string
+
+	// ProvidersMirrorArgs also inherits from the TfArgs interface
+	TfArgs
+}
+
+func (po *ProvidersMirrorArgsOptions) GetArgPlatforms() []string {
+	return getPlatformArgs(po.Platforms)
+}
+
+func (po *ProvidersMirrorArgsOptions) GetArgPlatformsValue() []string {
+	return po.Platforms
+}
+
+func (po *ProvidersMirrorArgsOptions) GetArgTargetDirValue() string {
+	return po.TargetDir
+}
+
+func (po *ProvidersMirrorArgsOptions) AreValid() error {
+	if po.TargetDir == "" {
+		return erroer.NewErrTerraformCoreInvalidArgumentError("the target directory of the providers mirror is empty", nil)
+	}
+
+	return arePlatformsValid(po.Platforms)
+}
@@ -0,0 +1,246 @@
+package terraformcore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/Excoriate/go-terradagger/pkg/erroer"
+)
+
+// UI event types emitted by terraform when it runs with -json.
+// See https://developer.hashicorp.com/terraform/internals/machine-readable-ui
+const (
+	UIEventTypeVersion        = "version"
+	UIEventTypeLog            = "log"
+	UIEventTypePlannedChange  = "planned_change"
+	UIEventTypeResourceDrift  = "resource_drift"
+	UIEventTypeChangeSummary  = "change_summary"
+	UIEventTypeOutputs        = "outputs"
+	UIEventTypeApplyStart     = "apply_start"
+	UIEventTypeApplyProgress  = "apply_progress"
+	UIEventTypeApplyComplete  = "apply_complete"
+	UIEventTypeApplyErrored   = "apply_errored"
+	UIEventTypeRefreshStart   = "refresh_start"
+	UIEventTypeRefreshDone    = "refresh_complete"
+	UIEventTypeDiagnostic     = "diagnostic"
+	UIEventTypeProvisionStart = "provision_start"
+)
+
+// UIEvent is a single event of the terraform machine-readable UI.
+type UIEvent interface {
+	GetType() string
+	GetLevel() string
+	GetMessage() string
+	GetTimestamp() time.Time
+}
+
+// UIEventBase holds the fields that are common to every UI event.
+type UIEventBase struct {
+	Level     string    `json:"@level"`
+	Message   string    `json:"@message"`
+	Module    string    `json:"@module"`
+	Timestamp time.Time `json:"@timestamp"`
+	Type      string    `json:"type"`
+}
+
+func (e *UIEventBase) GetType() string {
+	return e.Type
+}
+
+func (e *UIEventBase) GetLevel() string {
+	return e.Level
+}
+
+func (e *UIEventBase) GetMessage() string {
+	return e.Message
+}
+
+func (e *UIEventBase) GetTimestamp() time.Time {
+	return e.Timestamp
+}
+
+// UIResource identifies the resource an event refers to.
+type UIResource struct {
+	Addr            string `json:"addr"`
+	Module          string `json:"module"`
+	Resource        string `json:"resource"`
+	ImpliedProvider string `json:"implied_provider"`
+	ResourceType    string `json:"resource_type"`
+	ResourceName    string `json:"resource_name"`
+	ResourceKey     any    `json:"resource_key"`
+}
+
+type PlannedChangeEvent struct {
+	UIEventBase
+	Change struct {
+		Resource UIResource `json:"resource"`
+		Action   string     `json:"action"`
+		Reason   string     `json:"reason,omitempty"`
+	} `json:"change"`
+}
+
+type ApplyStartEvent struct {
+	UIEventBase
+	Hook struct {
+		Resource UIResource `json:"resource"`
+		Action   string     `json:"action"`
+		IDKey    string     `json:"id_key,omitempty"`
+		IDValue  string     `json:"id_value,omitempty"`
+	} `json:"hook"`
+}
+
+type ApplyCompleteEvent struct {
+	UIEventBase
+	Hook struct {
+		Resource       UIResource `json:"resource"`
+		Action         string     `json:"action"`
+		IDKey          string     `json:"id_key,omitempty"`
+		IDValue        string     `json:"id_value,omitempty"`
+		ElapsedSeconds int        `json:"elapsed_seconds"`
+	} `json:"hook"`
+}
+
+type ApplyErroredEvent struct {
+	UIEventBase
+	Hook struct {
+		Resource       UIResource `json:"resource"`
+		Action         string     `json:"action"`
+		ElapsedSeconds int        `json:"elapsed_seconds"`
+	} `json:"hook"`
+}
+
+type DiagnosticEvent struct {
+	UIEventBase
+	Diagnostic struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+		Address  string `json:"address,omitempty"`
+		Range    *struct {
+			Filename string `json:"filename"`
+			Start    struct {
+				Line   int `json:"line"`
+				Column int `json:"column"`
+			} `json:"start"`
+		} `json:"range,omitempty"`
+	} `json:"diagnostic"`
+}
+
+type ChangeSummaryEvent struct {
+	UIEventBase
+	Changes struct {
+		Add       int    `json:"add"`
+		Change    int    `json:"change"`
+		Import    int    `json:"import"`
+		Remove    int    `json:"remove"`
+		Operation string `json:"operation"`
+	} `json:"changes"`
+}
+
+// GenericUIEvent is used for the event types that don't have a dedicated Go type.
+type GenericUIEvent struct {
+	UIEventBase
+	Raw json.RawMessage `json:"-"`
+}
+
+// ParseUIEvent parses a single line of the terraform -json output.
+func ParseUIEvent(line []byte) (UIEvent, error) {
+	var base UIEventBase
+	if err := json.Unmarshal(line, &base); err != nil {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the line is not a valid UI event", err)
+	}
+
+	var event UIEvent
+	switch base.Type {
+	case UIEventTypePlannedChange:
+		event = &PlannedChangeEvent{}
+	case UIEventTypeApplyStart:
+		event = &ApplyStartEvent{}
+	case UIEventTypeApplyComplete:
+		event = &ApplyCompleteEvent{}
+	case UIEventTypeApplyErrored:
+		event = &ApplyErroredEvent{}
+	case UIEventTypeDiagnostic:
+		event = &DiagnosticEvent{}
+	case UIEventTypeChangeSummary:
+		event = &ChangeSummaryEvent{}
+	default:
+		return &GenericUIEvent{UIEventBase: base, Raw: append(json.RawMessage{}, line...)}, nil
+	}
+
+	if err := json.Unmarshal(line, event); err != nil {
+		return nil, erroer.NewErrTerraformCoreInvalidArgumentError("the UI event "+base.Type+" is not valid", err)
+	}
+
+	return event, nil
+}
+
+// ParseUIStream reads the terraform -json output line by line, and sends every event into the channel.
+// Lines that aren't JSON objects (e.g. the output of other commands) are skipped. The channel isn't closed.
+func ParseUIStream(ctx context.Context, r io.Reader, events chan<- UIEvent) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		event, err := ParseUIEvent([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ParseUIEvents parses the whole -json output of a command, e.g. the one returned by ApplyE.
+func ParseUIEvents(out string) ([]UIEvent, error) {
+	events := make(chan UIEvent)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- ParseUIStream(context.Background(), strings.NewReader(out), events)
+		close(events)
+	}()
+
+	var parsed []UIEvent
+	for event := range events {
+		parsed = append(parsed, event)
+	}
+
+	return parsed, <-errCh
+}
+
+// NewUIEventsStepLog returns a step log that parses the -json output of each step, and sends the events
+// into the channel while the step runs (as its lines are read, see container.StepLog.PollInterval).
+// Every event is delivered (including apply_errored, diagnostic and change_summary): the run waits until
+// the channel takes it, so read the channel concurrently with the run. Cancelling the context stops
+// the sending. The caller owns (and closes) the channel.
+func NewUIEventsStepLog(ctx context.Context, events chan<- UIEvent) *container.StepLog {
+	return &container.StepLog{
+		OnOutput: func(output container.StepOutput) {
+			parsed, _ := ParseUIEvents(output.Stdout)
+			for _, event := range parsed {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		},
+	}
+}
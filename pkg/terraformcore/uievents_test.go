@@ -0,0 +1,107 @@
+package terraformcore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Excoriate/go-terradagger/pkg/container"
+	"github.com/stretchr/testify/assert"
+)
+
+const uiEventsTestOutput = `{"@level":"info","@message":"Terraform 1.7.0","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:00.000000Z","terraform":"1.7.0","type":"version","ui":"1.2"}
+{"@level":"info","@message":"random_string.this: Plan to create","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:01.000000Z","change":{"resource":{"addr":"random_string.this","module":"","resource":"random_string.this","implied_provider":"random","resource_type":"random_string","resource_name":"this","resource_key":null},"action":"create"},"type":"planned_change"}
+{"@level":"info","@message":"Plan: 1 to add, 0 to change, 0 to destroy.","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:01.000000Z","changes":{"add":1,"change":0,"import":0,"remove":0,"operation":"apply"},"type":"change_summary"}
+not a json line
+{"@level":"info","@message":"random_string.this: Creating...","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:02.000000Z","hook":{"resource":{"addr":"random_string.this","resource_type":"random_string"},"action":"create"},"type":"apply_start"}
+{"@level":"info","@message":"random_string.this: Creation complete after 0s [id=abc]","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:02.000000Z","hook":{"resource":{"addr":"random_string.this","resource_type":"random_string"},"action":"create","id_key":"id","id_value":"abc","elapsed_seconds":0},"type":"apply_complete"}
+{"@level":"error","@message":"Error: Invalid value","@module":"terraform.ui","@timestamp":"2024-02-16T10:00:03.000000Z","diagnostic":{"severity":"error","summary":"Invalid value","detail":"The value is not valid.","address":"aws_s3_bucket.this"},"type":"diagnostic"}`
+
+func TestParseUIEvents(t *testing.T) {
+	events, err := ParseUIEvents(uiEventsTestOutput)
+	assert.NoError(t, err)
+	assert.Len(t, events, 6)
+
+	assert.IsType(t, &GenericUIEvent{}, events[0])
+	assert.Equal(t, UIEventTypeVersion, events[0].GetType())
+
+	planned, ok := events[1].(*PlannedChangeEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "random_string.this", planned.Change.Resource.Addr)
+	assert.Equal(t, "create", planned.Change.Action)
+
+	summary, ok := events[2].(*ChangeSummaryEvent)
+	assert.True(t, ok)
+	assert.Equal(t, 1, summary.Changes.Add)
+
+	assert.IsType(t, &ApplyStartEvent{}, events[3])
+
+	complete, ok := events[4].(*ApplyCompleteEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "abc", complete.Hook.IDValue)
+
+	diagnostic, ok := events[5].(*DiagnosticEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "error", diagnostic.GetLevel())
+	assert.Equal(t, "aws_s3_bucket.this", diagnostic.Diagnostic.Address)
+}
+
+func TestNewUIEventsStepLog(t *testing.T) {
+	events := make(chan UIEvent, 10)
+	stepLog := NewUIEventsStepLog(context.Background(), events)
+
+	// The lines of a running step arrive in several outputs.
+	lines := strings.Split(uiEventsTestOutput, "\n")
+	stepLog.OnOutput(container.StepOutput{Step: "terraform apply", Stdout: strings.Join(lines[:2], "\n") + "\n"})
+	stepLog.OnOutput(container.StepOutput{Step: "terraform apply", Stdout: lines[2] + "\n"})
+	close(events)
+
+	var types []string
+	for event := range events {
+		types = append(types, event.GetType())
+	}
+
+	assert.Equal(t, []string{UIEventTypeVersion, UIEventTypePlannedChange, UIEventTypeChangeSummary}, types)
+}
+
+func TestNewUIEventsStepLog_Unbuffered(t *testing.T) {
+	// Every event is delivered, even if the channel has no buffer, as long as it's read concurrently.
+	events := make(chan UIEvent)
+	stepLog := NewUIEventsStepLog(context.Background(), events)
+
+	go func() {
+		stepLog.OnOutput(container.StepOutput{Step: "terraform apply", Stdout: uiEventsTestOutput})
+		close(events)
+	}()
+
+	var types []string
+	for event := range events {
+		types = append(types, event.GetType())
+	}
+
+	expected, err := ParseUIEvents(uiEventsTestOutput)
+	assert.NoError(t, err)
+	assert.Len(t, types, len(expected))
+	assert.Contains(t, types, UIEventTypeDiagnostic)
+}
+
+func TestNewUIEventsStepLog_Cancelled(t *testing.T) {
+	// Nobody reads the channel: cancelling the context unblocks the run.
+	ctx, cancel := context.WithCancel(context.Background())
+	stepLog := NewUIEventsStepLog(ctx, make(chan UIEvent))
+
+	done := make(chan struct{})
+	go func() {
+		stepLog.OnOutput(container.StepOutput{Step: "terraform apply", Stdout: uiEventsTestOutput})
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the step log is still blocked after the context was cancelled")
+	}
+}
@@ -16,8 +16,12 @@ type ApplyOptions struct {
 	TerraformVarFiles []string
 	// Vars is a list of terraform vars to use
 	Vars []terraformcore.TFInputVariable
+	// JSON is a flag to output the machine-readable UI, equivalent to terraform apply -json
+	JSON bool
 	// AutoApprove is a flag to auto approve the plan
 	AutoApprove bool
+	// Guardrails, if set, inspects the plan and refuses destructive changes before running the command
+	Guardrails *terraformcore.Guardrails
 }
 
 func Apply(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ApplyOptions, _ terraformcore.TerragruntConfig) (*dagger.Container, container.Runtime, error) {
@@ -28,6 +32,8 @@ func Apply(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options App
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
 		AutoApprove:       options.AutoApprove,
+		Guardrails:        options.Guardrails,
+		JSON:              options.JSON,
 		TfGlobalOptions:   tfOpts,
 	})
 }
@@ -40,6 +46,25 @@ func ApplyE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options Ap
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
 		AutoApprove:       options.AutoApprove,
+		Guardrails:        options.Guardrails,
+		JSON:              options.JSON,
+		TfGlobalOptions:   tfOpts,
+	})
+}
+
+// ApplyAndVerifyIdempotentE applies (auto-approved), and then runs plan -detailed-exitcode on the same
+// container. If the plan still shows changes, it returns an erroer.ErrTerraformCoreNotIdempotentError
+// listing the resources.
+func ApplyAndVerifyIdempotentE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options ApplyOptions, _ terraformcore.TerragruntConfig) (string, error) {
+	tgRun := terraformcore.NewTerragruntRunner(td, tfOpts, nil)
+
+	return tgRun.RunApplyAndVerifyIdempotentE(config.IacToolTerragrunt, &terraformcore.ApplyArgsOptions{
+		RefreshOnly:       options.RefreshOnly,
+		TerraformVarFiles: options.TerraformVarFiles,
+		Vars:              options.Vars,
+		AutoApprove:       true,
+		Guardrails:        options.Guardrails,
+		JSON:              options.JSON,
 		TfGlobalOptions:   tfOpts,
 	})
 }
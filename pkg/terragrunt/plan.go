@@ -16,6 +16,11 @@ type PlanOptions struct {
 	TerraformVarFiles []string
 	// Vars is a list of terraform vars to use
 	Vars []terraformcore.TFInputVariable
+	// JSON is a flag to output the machine-readable UI, equivalent to terraform plan -json
+	JSON bool
+	// OutFile is the path (relative to the module) where the plan is saved. Equivalent to
+	// terraform plan -out
+	OutFile string
 }
 
 func Plan(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options PlanOptions, _ terraformcore.TerragruntConfig) (*dagger.Container, container.Runtime, error) {
@@ -25,6 +30,8 @@ func Plan(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options Plan
 		RefreshOnly:       options.RefreshOnly,
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
+		OutFile:           options.OutFile,
+		JSON:              options.JSON,
 		TfGlobalOptions:   tfOpts,
 	})
 }
@@ -36,6 +43,27 @@ func PlanE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options Pla
 		RefreshOnly:       options.RefreshOnly,
 		TerraformVarFiles: options.TerraformVarFiles,
 		Vars:              options.Vars,
+		OutFile:           options.OutFile,
+		JSON:              options.JSON,
+		TfGlobalOptions:   tfOpts,
+	})
+}
+
+// PlanResultE runs the plan, and returns it as a structured result (JSON plan, and human-readable output).
+// If no out file is set, the plan is saved into terraformcore.DefaultPlanOutFile.
+func PlanResultE(td *terradagger.TD, tfOpts terraformcore.TfGlobalOptions, options PlanOptions, _ terraformcore.TerragruntConfig) (*terraformcore.PlanResult, error) {
+	tgRun := terraformcore.NewTerragruntRunner(td, tfOpts, nil)
+
+	outFile := options.OutFile
+	if outFile == "" {
+		outFile = terraformcore.DefaultPlanOutFile
+	}
+
+	return tgRun.RunPlanResultE(config.IacToolTerragrunt, &terraformcore.PlanArgsOptions{
+		RefreshOnly:       options.RefreshOnly,
+		TerraformVarFiles: options.TerraformVarFiles,
+		Vars:              options.Vars,
+		OutFile:           outFile,
 		TfGlobalOptions:   tfOpts,
 	})
 }
@@ -1,6 +1,9 @@
 package utils
 
-import "os"
+import (
+	"fmt"
+	"os"
+)
 
 func GetSSHAuthSock() string {
 	return os.Getenv("SSH_AUTH_SOCK")
@@ -15,3 +18,15 @@ func GetSSHAuthSock() string {
 func GetSSHGitSecureConnectCommand() string {
 	return "ssh -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=accept-new"
 }
+
+// GetSSHGitKnownHostsConnectCommand returns an SSH command string that verifies the host keys against
+// the given known hosts file. In strict mode, the hosts that aren't in the file are rejected; otherwise,
+// their keys are accepted, but a changed key of a known host is still rejected.
+func GetSSHGitKnownHostsConnectCommand(knownHostsFile string, strict bool) string {
+	strictHostKeyChecking := "accept-new"
+	if strict {
+		strictHostKeyChecking = "yes"
+	}
+
+	return fmt.Sprintf("ssh -o UserKnownHostsFile=%s -o StrictHostKeyChecking=%s", knownHostsFile, strictHostKeyChecking)
+}